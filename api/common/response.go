@@ -12,6 +12,7 @@ type MetaData struct {
 	PageCount  int64               `json:"page_count"`
 	TotalCount int64               `json:"total_count"`
 	Links      []map[string]string `json:"links"`
+	NextCursor string              `json:"next_cursor,omitempty"`
 }
 
 // ResponseDTO Http response dto
@@ -73,6 +74,24 @@ func GenerateUnauthorizedResponse(c echo.Context, data interface{}, message stri
 	})
 }
 
+// GenerateConflictResponse Http conflict response
+func GenerateConflictResponse(c echo.Context, data interface{}, message string) error {
+	return c.JSON(http.StatusConflict, ResponseDTO{
+		Status:  "conflict",
+		Message: message,
+		Data:    data,
+	})
+}
+
+// GenerateTooManyRequestsResponse Http too many requests response
+func GenerateTooManyRequestsResponse(c echo.Context, data interface{}, message string) error {
+	return c.JSON(http.StatusTooManyRequests, ResponseDTO{
+		Status:  "too_many_requests",
+		Message: message,
+		Data:    data,
+	})
+}
+
 // GetPaginationMetadata return pagination metadata
 func GetPaginationMetadata(page, limit, totalRecords, totalPaginatedRecords int64) MetaData {
 	metaData := MetaData{
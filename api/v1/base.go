@@ -9,4 +9,9 @@ func Router(g *echo.Group) {
 	MovieRouter(g.Group("/movies"))
 	ReviewRouter(g.Group("/reviews"))
 	CommentRouter(g.Group("/comments"))
+	ModerationRouter(g.Group("/moderation"))
+	NotificationRouter(g.Group("/notifications"))
+	ListRouter(g.Group("/lists"))
+	FeedRouter(g.Group("/feed"))
+	ExportRouter(g.Group("/exports"))
 }
@@ -4,16 +4,25 @@ import (
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	"github.com/niloydeb1/Golang-Movie_API/api/common"
+	"github.com/niloydeb1/Golang-Movie_API/config"
 	"github.com/niloydeb1/Golang-Movie_API/enums"
 	v1 "github.com/niloydeb1/Golang-Movie_API/src/v1"
+	"go.mongodb.org/mongo-driver/bson"
 	"log"
+	"net/url"
+	"strconv"
 	"time"
 )
 
 func CommentRouter(g *echo.Group) {
 	g.GET("/:id", commentApi{}.GetByID)
+	g.GET("/:id/revisions", commentApi{}.GetRevisions)
 	g.POST("", commentApi{}.Post)
+	g.PUT("/:id", commentApi{}.Put)
 	g.DELETE("/:id", commentApi{}.Delete)
+	g.POST("/:id/reports", commentApi{}.Report)
+	g.GET("/:id/reactions", commentApi{}.GetReactions)
+	g.POST("/:id/reactions", commentApi{}.React)
 }
 
 type commentApi struct {
@@ -35,15 +44,22 @@ func (c commentApi) GetByID(context echo.Context) error {
 		return common.GenerateErrorResponse(context, "[ERROR]: Comment id is not provided", "Operation failed")
 	}
 	data := v1.Comment{}.GetByID(id)
-	if data.ID == "" {
+	if data.ID == "" || (!data.Moderation.IsVisible() && !canSeeModeratedContent(context, data.CommenterId)) {
 		return common.GenerateErrorResponse(context, "[ERROR]: Comment is not found!", "Please provide a valid comment id!")
 	}
+	if data.ListId != "" {
+		userFromToken, _ := getOptionalUserTokenDto(context)
+		list := v1.MovieList{}.GetByID(data.ListId)
+		if list.ID == "" || (!list.IsVisibleTo(userFromToken.ID) && !isAdmin(userFromToken)) {
+			return common.GenerateErrorResponse(context, "[ERROR]: Comment is not found!", "Please provide a valid comment id!")
+		}
+	}
 	return common.GenerateSuccessResponse(context, data, nil, "Operation Successful")
 }
 
 // Post... Post Api
 // @Summary Post comment api
-// @Description Api for posting comment
+// @Description Api for posting comment on a review, or on a public or unlisted movie list by setting list_id, or on a movie discussion by setting movie_id without review_id. Set parent_id to reply to a comment
 // @Tags Comment
 // @Produce json
 // @Param Authorization header string true "Insert your access token while posting comment" default(Bearer <Add access token here>)
@@ -69,25 +85,200 @@ func (c commentApi) Post(context echo.Context) error {
 	if err != nil {
 		return common.GenerateErrorResponse(context, "[ERROR]: Invalid data provided", err.Error())
 	}
-	review := v1.Review{}.GetByID(commentDto.ReviewId)
-	if review.ID == "" {
-		return common.GenerateErrorResponse(context, "[ERROR]: Review is not found", "Operation Failed")
+	commentDto.RootId, commentDto.Depth = "", 0
+	var authorIds []string
+	if commentDto.ParentId != "" {
+		parent := v1.Comment{}.GetByID(commentDto.ParentId)
+		if parent.ID == "" || parent.Deleted || !parent.Moderation.IsVisible() {
+			return common.GenerateErrorResponse(context, "[ERROR]: Parent comment is not found", "Operation Failed")
+		}
+		if commentDto.ReviewId != "" && commentDto.ReviewId != parent.ReviewId {
+			return common.GenerateErrorResponse(context, "[ERROR]: Invalid data provided", "parent comment belongs to another review")
+		}
+		if commentDto.MovieId != "" && commentDto.MovieId != parent.MovieId {
+			return common.GenerateErrorResponse(context, "[ERROR]: Invalid data provided", "parent comment belongs to another movie")
+		}
+		if commentDto.ListId != "" && commentDto.ListId != parent.ListId {
+			return common.GenerateErrorResponse(context, "[ERROR]: Invalid data provided", "parent comment belongs to another list")
+		}
+		if parent.Depth >= config.CommentMaxDepth {
+			return common.GenerateErrorResponse(context, "[ERROR]: Invalid data provided", "maximum reply depth of "+strconv.FormatInt(config.CommentMaxDepth, 10)+" is reached")
+		}
+		commentDto.ReviewId = parent.ReviewId
+		commentDto.MovieId = parent.MovieId
+		commentDto.ListId = parent.ListId
+		commentDto.RootId = parent.RootId
+		if commentDto.RootId == "" {
+			commentDto.RootId = parent.ID
+		}
+		commentDto.Depth = parent.Depth + 1
+		authorIds = append(authorIds, parent.CommenterId)
+	}
+	if commentDto.ReviewId != "" {
+		review := v1.Review{}.GetByID(commentDto.ReviewId)
+		if review.ID == "" || !review.Moderation.IsVisible() {
+			return common.GenerateErrorResponse(context, "[ERROR]: Review is not found", "Operation Failed")
+		}
+		commentDto.MovieId = review.Movie.ID
+		commentDto.ListId = ""
+		authorIds = append(authorIds, review.ReviewerId)
+	} else if commentDto.ListId != "" {
+		list := v1.MovieList{}.GetByID(commentDto.ListId)
+		if list.ID == "" || list.Visibility == enums.PRIVATE_LIST {
+			return common.GenerateErrorResponse(context, "[ERROR]: List is not found", "Operation Failed")
+		}
+		commentDto.MovieId = ""
+		authorIds = append(authorIds, list.OwnerId)
+	} else if (v1.Movie{}).GetByID(commentDto.MovieId).ID == "" {
+		return common.GenerateErrorResponse(context, "[ERROR]: Movie is not found", "Operation Failed")
+	}
+	if (v1.UserRelation{}).IsBlockedByAny(userFromToken.ID, authorIds...) {
+		return common.GenerateForbiddenResponse(context, "[ERROR]: Insufficient permission", "You are blocked by the author!")
 	}
-	commentDto.MovieId = review.Movie.ID
 	commentDto.ID = uuid.New().String()
+	commentDto.Deleted = false
+	commentDto.Reactions = map[string]int64{}
 	commentDto.CommenterId = userFromToken.ID
 	commentDto.CommenterEmail = userFromToken.Email
 	commentDto.CreatedAt = time.Now().UTC()
+	commentDto.Moderation = v1.NewModeration(v1.User{}.GetByID(userFromToken.ID))
+	filterResult, err := filterContent(enums.COMMENT, commentDto.ID, commentDto.CommenterId,
+		v1.FilterField{Name: "comment", Text: &commentDto.Comment})
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: Comment is rejected by content filter", err.Error())
+	}
+	if filterResult.Flagged {
+		commentDto.Moderation = flaggedModeration(filterResult)
+	}
+	commentDto = commentDto.RenderMentions()
 	err = v1.Comment{}.Store(commentDto)
 	if err != nil {
 		return common.GenerateErrorResponse(context, err, err.Error())
 	}
+	if commentDto.Moderation.State == enums.PENDING {
+		return common.GenerateSuccessResponse(context, "[SUCCESS]: Comment is submitted for moderation", nil, "Operation Successful")
+	}
+	notifyComment(commentDto)
+	publishComment(commentDto)
 	return common.GenerateSuccessResponse(context, "[SUCCESS]: Comment is posted successfully", nil, "Operation Successful")
 }
 
+// Put... Put Api
+// @Summary Update comment api
+// @Description Api for editing a comment. The author can edit within the configured edit window after posting, admins can always edit
+// @Tags Comment
+// @Produce json
+// @Param Authorization header string true "Insert your access token while updating comment" default(Bearer <Add access token here>)
+// @Param id path string true "comment id"
+// @Param data body v1.CommentUpdateDto true "dto for updating comment"
+// @Success 200 {object} common.ResponseDTO{data=v1.Comment{}}
+// @Failure 400 {object} common.ResponseDTO
+// @Forbidden 403 {object} common.ResponseDTO
+// @Router /api/v1/comments/{id} [PUT]
+func (c commentApi) Put(context echo.Context) error {
+	userFromToken, err := GetUserTokenDtoFromBearerToken(context, v1.Jwt{})
+	if err != nil {
+		return common.GenerateErrorResponse(context, err.Error(), "Operation Failed!")
+	}
+	if userFromToken.ID == "" {
+		return common.GenerateUnauthorizedResponse(context, "[ERROR]: User not found!", "Please provide valid user information.")
+	}
+	id := context.Param("id")
+	if id == "" {
+		return common.GenerateErrorResponse(context, "[ERROR]: Comment id is not provided", "Operation failed")
+	}
+	comment := v1.Comment{}.GetByID(id)
+	if comment.ID == "" || (!comment.Moderation.IsVisible() && !canSeeModeratedContent(context, comment.CommenterId)) {
+		return common.GenerateErrorResponse(context, "[ERROR]: Comment is not found!", "Please provide a valid comment id!")
+	}
+	if comment.Deleted {
+		return common.GenerateErrorResponse(context, "[ERROR]: Comment is deleted!", "A deleted comment can not be edited!")
+	}
+	if !comment.IsEditableBy(userFromToken.ID, userFromToken.Role, time.Now().UTC()) {
+		return common.GenerateForbiddenResponse(context, "[ERROR]: Insufficient permission",
+			"Only the author can edit a comment within "+strconv.FormatInt(config.CommentEditWindowMinutes, 10)+" minutes of posting!")
+	}
+	updateDto := v1.CommentUpdateDto{}
+	if err := context.Bind(&updateDto); err != nil {
+		log.Println("Input Error:", err.Error())
+		return common.GenerateErrorResponse(context, nil, "Failed to Bind Input!")
+	}
+	edited := comment
+	edited.Comment = updateDto.Comment
+	if updateDto.Spoiler != nil {
+		edited.Spoiler = *updateDto.Spoiler
+	}
+	err = edited.Validate()
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: Invalid data provided", err.Error())
+	}
+	if edited.Comment == comment.Comment && edited.Spoiler == comment.Spoiler {
+		return common.GenerateSuccessResponse(context, comment, nil, "Nothing to update")
+	}
+	filterResult, err := filterContent(enums.COMMENT, comment.ID, comment.CommenterId,
+		v1.FilterField{Name: "comment", Text: &edited.Comment})
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: Comment is rejected by content filter", err.Error())
+	}
+	if filterResult.Flagged {
+		edited.Moderation = flaggedModeration(filterResult)
+	}
+	edited = edited.RenderMentions()
+	err = v1.CommentRevision{}.Store(v1.NewCommentRevision(uuid.New().String(), userFromToken.ID, comment, edited))
+	if err != nil {
+		return common.GenerateErrorResponse(context, err, err.Error())
+	}
+	editedAt := time.Now().UTC()
+	edited.EditedAt = &editedAt
+	err = v1.Comment{}.Update(edited)
+	if err != nil {
+		return common.GenerateErrorResponse(context, err, err.Error())
+	}
+	if edited.Moderation.IsVisible() {
+		notifyMentions(enums.COMMENT, edited.ID, edited.CommenterId, edited.CommenterEmail, comment.Mentions, edited.Mentions)
+	}
+	return common.GenerateSuccessResponse(context, edited, nil, "Operation Successful")
+}
+
+// GetRevisions... Get Revisions Api
+// @Summary Get comment revisions api
+// @Description Api for getting edit history of a comment, visible to its author and admins
+// @Tags Comment
+// @Produce json
+// @Param Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param id path string true "comment id"
+// @Param page query string false "page"
+// @Param limit query string false "limit"
+// @Success 200 {object} common.ResponseDTO{data=[]v1.CommentRevision{}}
+// @Forbidden 403 {object} common.ResponseDTO
+// @Failure 400 {object} common.ResponseDTO
+// @Router /api/v1/comments/{id}/revisions [GET]
+func (c commentApi) GetRevisions(context echo.Context) error {
+	userFromToken, err := GetUserTokenDtoFromBearerToken(context, v1.Jwt{})
+	if err != nil {
+		return common.GenerateErrorResponse(context, err.Error(), "Operation Failed!")
+	}
+	id := context.Param("id")
+	if id == "" {
+		return common.GenerateErrorResponse(context, "[ERROR]: Comment id is not provided", "Operation failed")
+	}
+	comment := v1.Comment{}.GetByID(id)
+	if comment.ID == "" {
+		return common.GenerateErrorResponse(context, "[ERROR]: Comment is not found!", "Please provide a valid comment id!")
+	}
+	if comment.CommenterId != userFromToken.ID && !isAdmin(userFromToken) {
+		return common.GenerateForbiddenResponse(context, "[ERROR]: Insufficient permission", "Operation Failed!")
+	}
+	pagination := getPagination(context)
+	data, total := v1.CommentRevision{}.GetByCommentId(id, pagination)
+	metadata := getPaginationMetadataWithLinks(context, pagination, total, int64(len(data)), nil)
+	return common.GenerateSuccessResponse(context, data,
+		&metadata, "Successful")
+}
+
 // Delete... Delete Api
 // @Summary Delete comment api
-// @Description Api for deleting comment
+// @Description Api for deleting comment, a comment with replies is replaced by a [deleted] placeholder
 // @Tags Comment
 // @Produce json
 // @Param Authorization header string true "Insert your access token while deleting comment" default(Bearer <Add access token here>)
@@ -115,9 +306,113 @@ func (c commentApi) Delete(context echo.Context) error {
 	if comment.CommenterId != userFromToken.ID && userFromToken.Role != enums.ADMIN && userFromToken.Role != enums.SUPERADMIN {
 		return common.GenerateUnauthorizedResponse(context, "[ERROR]: Insufficient permission", "Operation Failed!")
 	}
-	err = v1.Comment{}.Delete(id)
+	if replies := (v1.Comment{}).CountReplies(id); replies > 0 {
+		err = v1.Comment{}.MarkDeleted(id)
+	} else {
+		err = v1.Comment{}.Delete(id)
+	}
 	if err != nil {
 		return common.GenerateErrorResponse(context, err, err.Error())
 	}
+	retractActivity(enums.COMMENT_POSTED, id)
 	return common.GenerateSuccessResponse(context, "[SUCCESS]: Comment is deleted successfully", nil, "Operation Successful")
 }
+
+// Report... Report Api
+// @Summary Report comment api
+// @Description Api for reporting an abusive comment, a user can report a comment once
+// @Tags Comment
+// @Produce json
+// @Param Authorization header string true "Insert your access token while reporting comment" default(Bearer <Add access token here>)
+// @Param id path string true "comment id"
+// @Param data body v1.ReportDto true "dto for reporting comment"
+// @Success 200 {object} common.ResponseDTO
+// @Failure 400 {object} common.ResponseDTO
+// @Forbidden 403 {object} common.ResponseDTO
+// @Failure 409 {object} common.ResponseDTO
+// @Router /api/v1/comments/{id}/reports [POST]
+func (c commentApi) Report(context echo.Context) error {
+	return postReport(context, enums.COMMENT)
+}
+
+// React... React Api
+// @Summary React to comment api
+// @Description Api for toggling a reaction to a comment, reacting again with the same emoji removes the reaction
+// @Tags Comment
+// @Produce json
+// @Param Authorization header string true "Insert your access token while reacting" default(Bearer <Add access token here>)
+// @Param id path string true "comment id"
+// @Param data body v1.ReactionDto true "dto for reacting"
+// @Success 200 {object} common.ResponseDTO{data=v1.ReactionResult{}}
+// @Failure 400 {object} common.ResponseDTO
+// @Forbidden 403 {object} common.ResponseDTO
+// @Router /api/v1/comments/{id}/reactions [POST]
+func (c commentApi) React(context echo.Context) error {
+	return postReaction(context, enums.COMMENT)
+}
+
+// GetReactions... Get Reactions Api
+// @Summary Get comment reactions api
+// @Description Api for listing who reacted to a comment
+// @Tags Comment
+// @Produce json
+// @Param id path string true "comment id"
+// @Param emoji query string false "emoji"
+// @Param page query string false "page"
+// @Param limit query string false "limit"
+// @Success 200 {object} common.ResponseDTO{data=[]v1.ReactionView{}}
+// @Failure 400 {object} common.ResponseDTO
+// @Router /api/v1/comments/{id}/reactions [GET]
+func (c commentApi) GetReactions(context echo.Context) error {
+	return getReactions(context, enums.COMMENT)
+}
+
+// listComments responds with visible comments matching the query in the requested sort order. By default
+// comments are listed one after another, view=tree nests replies under their parents and view=flat lists
+// threads depth first. Threaded views are sorted and paginated by top level comments.
+func listComments(context echo.Context, query bson.M) error {
+	sort, err := getCommentSort(context)
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: Invalid sort is provided", err.Error())
+	}
+	pagination := getPagination(context)
+	view := context.QueryParam("view")
+	hidden := hiddenUserIds(context)
+	query = v1.ExcludeUsersQuery(query, "commenter_id", hidden)
+	maskSpoilers := !showSpoilers(context)
+	var data interface{}
+	var count, total int64
+	switch view {
+	case "":
+		comments, commentTotal := v1.Comment{}.Search(v1.VisibleQuery(query), pagination, sort)
+		if maskSpoilers {
+			for i := range comments {
+				comments[i] = comments[i].MaskSpoilers()
+			}
+		}
+		data, count, total = comments, int64(len(comments)), commentTotal
+	case "tree", "flat":
+		threads, threadTotal := v1.Comment{}.GetThreads(query, pagination, sort)
+		threads = v1.WithoutCommenters(threads, hidden)
+		if maskSpoilers {
+			for i := range threads {
+				threads[i] = threads[i].MaskSpoilers()
+			}
+		}
+		count, total = int64(len(threads)), threadTotal
+		if view == "tree" {
+			data = threads
+		} else {
+			data = v1.FlattenCommentThreads(threads)
+		}
+	default:
+		return common.GenerateErrorResponse(context, "[ERROR]: Invalid view is provided", "view must be one of [tree/flat]")
+	}
+	values := url.Values{"sort": {string(sort)}}
+	if view != "" {
+		values.Set("view", view)
+	}
+	metadata := getPaginationMetadataWithLinks(context, pagination, total, count, values)
+	return common.GenerateSuccessResponse(context, data,
+		&metadata, "Successful")
+}
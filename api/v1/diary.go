@@ -0,0 +1,284 @@
+package v1
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/niloydeb1/Golang-Movie_API/api/common"
+	"github.com/niloydeb1/Golang-Movie_API/enums"
+	v1 "github.com/niloydeb1/Golang-Movie_API/src/v1"
+	"log"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+type diaryApi struct {
+}
+
+// Get... Get Api
+// @Summary Get own diary api
+// @Description Api for getting own diary entries, latest viewing first, optionally of a year or a month of a year
+// @Tags Diary
+// @Produce json
+// @Param Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param year query string false "year"
+// @Param month query string false "month [1-12], requires year"
+// @Param page query string false "page"
+// @Param limit query string false "limit"
+// @Success 200 {object} common.ResponseDTO{data=[]v1.DiaryEntry{}}
+// @Forbidden 403 {object} common.ResponseDTO
+// @Failure 400 {object} common.ResponseDTO
+// @Router /api/v1/users/me/diary [GET]
+func (d diaryApi) Get(context echo.Context) error {
+	userFromToken, err := GetUserTokenDtoFromBearerToken(context, v1.Jwt{})
+	if err != nil {
+		return common.GenerateErrorResponse(context, err.Error(), "Operation Failed!")
+	}
+	from, to, err := getDiaryDateRange(context, false)
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: Invalid date range is provided", err.Error())
+	}
+	pagination := getPagination(context)
+	data, total := v1.DiaryEntry{}.GetByUserId(userFromToken.ID, from, to, pagination)
+	query := url.Values{}
+	for _, key := range []string{"year", "month"} {
+		if value := context.QueryParam(key); value != "" {
+			query.Set(key, value)
+		}
+	}
+	metadata := getPaginationMetadataWithLinks(context, pagination, total, int64(len(data)), query)
+	return common.GenerateSuccessResponse(context, data, &metadata, "Successful")
+}
+
+// GetCalendar... Get Calendar Api
+// @Summary Get own diary calendar api
+// @Description Api for getting own diary entries of a year or a month grouped by day, in date order
+// @Tags Diary
+// @Produce json
+// @Param Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param year query string true "year"
+// @Param month query string false "month [1-12]"
+// @Success 200 {object} common.ResponseDTO{data=[]v1.CalendarDay{}}
+// @Forbidden 403 {object} common.ResponseDTO
+// @Failure 400 {object} common.ResponseDTO
+// @Router /api/v1/users/me/diary/calendar [GET]
+func (d diaryApi) GetCalendar(context echo.Context) error {
+	userFromToken, err := GetUserTokenDtoFromBearerToken(context, v1.Jwt{})
+	if err != nil {
+		return common.GenerateErrorResponse(context, err.Error(), "Operation Failed!")
+	}
+	from, to, err := getDiaryDateRange(context, true)
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: Invalid date range is provided", err.Error())
+	}
+	return common.GenerateSuccessResponse(context, v1.DiaryEntry{}.GetCalendar(userFromToken.ID, from, to), nil, "Successful")
+}
+
+// GetByID... GetByID Api
+// @Summary Get own diary entry api
+// @Description Api for getting an own diary entry by id
+// @Tags Diary
+// @Produce json
+// @Param Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param id path string true "diary entry id"
+// @Success 200 {object} common.ResponseDTO{data=v1.DiaryEntry{}}
+// @Forbidden 403 {object} common.ResponseDTO
+// @Failure 400 {object} common.ResponseDTO
+// @Router /api/v1/users/me/diary/{id} [GET]
+func (d diaryApi) GetByID(context echo.Context) error {
+	userFromToken, err := GetUserTokenDtoFromBearerToken(context, v1.Jwt{})
+	if err != nil {
+		return common.GenerateErrorResponse(context, err.Error(), "Operation Failed!")
+	}
+	entry := v1.DiaryEntry{}.GetByID(userFromToken.ID, context.Param("id"))
+	if entry.ID == "" {
+		return common.GenerateErrorResponse(context, "[ERROR]: Diary entry is not found!", "Please provide a valid diary entry id!")
+	}
+	return common.GenerateSuccessResponse(context, entry, nil, "Successful")
+}
+
+// Post... Post Api
+// @Summary Log a viewing api
+// @Description Api for logging a viewing of a movie to own diary, by movie id or title. A movie that is not stored yet is fetched by title
+// @Tags Diary
+// @Produce json
+// @Param Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param data body v1.DiaryEntryDto true "dto for logging a viewing, rewatch is derived from earlier entries if omitted"
+// @Success 200 {object} common.ResponseDTO{data=v1.DiaryEntry{}}
+// @Forbidden 403 {object} common.ResponseDTO
+// @Failure 400 {object} common.ResponseDTO
+// @Router /api/v1/users/me/diary [POST]
+func (d diaryApi) Post(context echo.Context) error {
+	userFromToken, err := GetUserTokenDtoFromBearerToken(context, v1.Jwt{})
+	if err != nil {
+		return common.GenerateErrorResponse(context, err.Error(), "Operation Failed!")
+	}
+	formData := v1.DiaryEntryDto{}
+	if err := context.Bind(&formData); err != nil {
+		log.Println("Input Error:", err.Error())
+		return common.GenerateErrorResponse(context, nil, "Failed to Bind Input!")
+	}
+	err = formData.Validate(time.Now())
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: Invalid data provided", err.Error())
+	}
+	var movie v1.Movie
+	if formData.MovieId != "" {
+		movie = v1.Movie{}.GetByID(formData.MovieId)
+		if movie.ID == "" {
+			err = errors.New("movie does not exist")
+		}
+	} else {
+		movie, err = getOrFetchMovie(formData.Title)
+	}
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: Movie is not found", err.Error())
+	}
+	if err := validateDiaryReview(userFromToken.ID, movie.ID, formData.ReviewId); err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: Invalid data provided", err.Error())
+	}
+	entry, err := logDiaryEntry(userFromToken.ID, movie, formData)
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: Failed to log viewing!", err.Error())
+	}
+	publishDiaryEntry(entry)
+	return common.GenerateSuccessResponse(context, entry, nil, "Operation Successful")
+}
+
+// Patch... Patch Api
+// @Summary Update own diary entry api
+// @Description Api for updating date, rewatch flag, rating or linked review of an own diary entry
+// @Tags Diary
+// @Produce json
+// @Param Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param id path string true "diary entry id"
+// @Param data body v1.DiaryUpdateDto true "dto for updating a diary entry, omitted fields are left unchanged"
+// @Success 200 {object} common.ResponseDTO{data=v1.DiaryEntry{}}
+// @Forbidden 403 {object} common.ResponseDTO
+// @Failure 400 {object} common.ResponseDTO
+// @Router /api/v1/users/me/diary/{id} [PATCH]
+func (d diaryApi) Patch(context echo.Context) error {
+	userFromToken, err := GetUserTokenDtoFromBearerToken(context, v1.Jwt{})
+	if err != nil {
+		return common.GenerateErrorResponse(context, err.Error(), "Operation Failed!")
+	}
+	entry := v1.DiaryEntry{}.GetByID(userFromToken.ID, context.Param("id"))
+	if entry.ID == "" {
+		return common.GenerateErrorResponse(context, "[ERROR]: Diary entry is not found!", "Please provide a valid diary entry id!")
+	}
+	formData := v1.DiaryUpdateDto{}
+	if err := context.Bind(&formData); err != nil {
+		log.Println("Input Error:", err.Error())
+		return common.GenerateErrorResponse(context, nil, "Failed to Bind Input!")
+	}
+	err = formData.Validate(time.Now())
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: Invalid data provided", err.Error())
+	}
+	if formData.WatchedOn != nil {
+		entry.WatchedOn = *formData.WatchedOn
+	}
+	if formData.Rewatch != nil {
+		entry.Rewatch = *formData.Rewatch
+	}
+	if formData.Rating != nil {
+		entry.Rating = formData.Rating
+	}
+	if formData.ReviewId != nil {
+		if err := validateDiaryReview(userFromToken.ID, entry.Movie.ID, *formData.ReviewId); err != nil {
+			return common.GenerateErrorResponse(context, "[ERROR]: Invalid data provided", err.Error())
+		}
+		entry.ReviewId = *formData.ReviewId
+	}
+	entry.UpdatedAt = time.Now().UTC()
+	err = v1.DiaryEntry{}.Update(entry)
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: Failed to update diary entry!", err.Error())
+	}
+	return common.GenerateSuccessResponse(context, entry, nil, "Operation Successful")
+}
+
+// Delete... Delete Api
+// @Summary Delete own diary entry api
+// @Description Api for deleting an own diary entry
+// @Tags Diary
+// @Produce json
+// @Param Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param id path string true "diary entry id"
+// @Success 200 {object} common.ResponseDTO
+// @Forbidden 403 {object} common.ResponseDTO
+// @Failure 400 {object} common.ResponseDTO
+// @Router /api/v1/users/me/diary/{id} [DELETE]
+func (d diaryApi) Delete(context echo.Context) error {
+	userFromToken, err := GetUserTokenDtoFromBearerToken(context, v1.Jwt{})
+	if err != nil {
+		return common.GenerateErrorResponse(context, err.Error(), "Operation Failed!")
+	}
+	err = v1.DiaryEntry{}.Delete(userFromToken.ID, context.Param("id"))
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: Failed to delete diary entry!", err.Error())
+	}
+	retractActivity(enums.DIARY_LOGGED, context.Param("id"))
+	return common.GenerateSuccessResponse(context, "[SUCCESS]: Diary entry is deleted", nil, "Operation Successful")
+}
+
+// logDiaryEntry stores a viewing of the movie by the user. Rewatch is derived from earlier
+// entries of the movie if the dto does not provide it.
+func logDiaryEntry(userId string, movie v1.Movie, dto v1.DiaryEntryDto) (v1.DiaryEntry, error) {
+	now := time.Now().UTC()
+	entry := v1.DiaryEntry{
+		ID:        uuid.New().String(),
+		UserId:    userId,
+		Movie:     v1.NewMovieSummary(movie),
+		WatchedOn: dto.WatchedOn,
+		Rating:    dto.Rating,
+		ReviewId:  dto.ReviewId,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if dto.Rewatch != nil {
+		entry.Rewatch = *dto.Rewatch
+	} else {
+		entry.Rewatch = v1.DiaryEntry{}.HasWatched(userId, movie.ID, dto.WatchedOn)
+	}
+	return entry, v1.DiaryEntry{}.Store(entry)
+}
+
+// validateDiaryReview returns an error if a diary entry of the movie can not be linked to the
+// review. Only own reviews of the same movie can be linked, an empty id links no review.
+func validateDiaryReview(userId, movieId, reviewId string) error {
+	if reviewId == "" {
+		return nil
+	}
+	review := v1.Review{}.GetByID(reviewId)
+	if review.ID == "" || review.ReviewerId != userId || review.Movie.ID != movieId {
+		return errors.New("review id must refer to your review of the same movie")
+	}
+	return nil
+}
+
+// getDiaryDateRange returns the date range of the year and month query params. Without a year
+// the range is unbounded unless the year is required.
+func getDiaryDateRange(context echo.Context, yearRequired bool) (string, string, error) {
+	yearParam, monthParam := context.QueryParam("year"), context.QueryParam("month")
+	if yearParam == "" {
+		if yearRequired || monthParam != "" {
+			return "", "", errors.New("year is required")
+		}
+		return "", "", nil
+	}
+	year, err := strconv.Atoi(yearParam)
+	if err != nil || year < 1 || year > 9999 {
+		return "", "", errors.New("year must be between 1 and 9999")
+	}
+	month := 0
+	if monthParam != "" {
+		month, err = strconv.Atoi(monthParam)
+		if err != nil || month < 1 || month > 12 {
+			return "", "", errors.New("month must be between 1 and 12")
+		}
+	}
+	from, to := v1.DiaryDateRange(year, month)
+	return from, to, nil
+}
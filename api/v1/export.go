@@ -0,0 +1,213 @@
+package v1
+
+import (
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/niloydeb1/Golang-Movie_API/api/common"
+	"github.com/niloydeb1/Golang-Movie_API/config"
+	"github.com/niloydeb1/Golang-Movie_API/enums"
+	v1 "github.com/niloydeb1/Golang-Movie_API/src/v1"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type exportApi struct {
+}
+
+// ExportRouter api/v1/exports router
+func ExportRouter(g *echo.Group) {
+	g.GET("/download", exportApi{}.Download)
+}
+
+// Post... Post Api
+// @Summary Request own data export api
+// @Description Api for requesting an export of own personal data. The export is built in the background into a zip of json and csv files, and a download link is mailed once it is ready
+// @Tags Export
+// @Produce json
+// @Param Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Success 200 {object} common.ResponseDTO{data=v1.DataExport{}}
+// @Forbidden 403 {object} common.ResponseDTO
+// @Failure 400 {object} common.ResponseDTO
+// @Failure 409 {object} common.ResponseDTO
+// @Router /api/v1/users/me/exports [POST]
+func (e exportApi) Post(context echo.Context) error {
+	userFromToken, err := GetUserTokenDtoFromBearerToken(context, v1.Jwt{})
+	if err != nil {
+		return common.GenerateErrorResponse(context, err.Error(), "Operation Failed!")
+	}
+	user := v1.User{}.GetByID(userFromToken.ID)
+	if user.ID == "" || user.Status == enums.DELETED {
+		return common.GenerateUnauthorizedResponse(context, "[ERROR]: User not found!", "Please provide valid user information.")
+	}
+	return requestDataExport(context, user, userFromToken.ID)
+}
+
+// Get... Get Api
+// @Summary Get own data exports api
+// @Description Api for getting own data exports, latest first. Completed exports that have not expired have a download link
+// @Tags Export
+// @Produce json
+// @Param Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param page query string false "page"
+// @Param limit query string false "limit"
+// @Success 200 {object} common.ResponseDTO{data=[]v1.DataExport{}}
+// @Forbidden 403 {object} common.ResponseDTO
+// @Failure 400 {object} common.ResponseDTO
+// @Router /api/v1/users/me/exports [GET]
+func (e exportApi) Get(context echo.Context) error {
+	userFromToken, err := GetUserTokenDtoFromBearerToken(context, v1.Jwt{})
+	if err != nil {
+		return common.GenerateErrorResponse(context, err.Error(), "Operation Failed!")
+	}
+	pagination := getPagination(context)
+	data, total := v1.DataExport{}.GetByUserId(userFromToken.ID, pagination)
+	for i := range data {
+		data[i], err = data[i].WithDownloadUrl()
+		if err != nil {
+			return common.GenerateErrorResponse(context, "[ERROR]: Failed to sign download link!", err.Error())
+		}
+	}
+	metadata := getPaginationMetadataWithLinks(context, pagination, total, int64(len(data)), nil)
+	return common.GenerateSuccessResponse(context, data, &metadata, "Successful")
+}
+
+// PostForUser... Post For User Api
+// @Summary Request data export of a user api
+// @Description Api for admins to request an export of personal data on behalf of a user. The download link is mailed to the user
+// @Tags Export
+// @Produce json
+// @Param Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param id path string true "user id"
+// @Success 200 {object} common.ResponseDTO{data=v1.DataExport{}}
+// @Forbidden 403 {object} common.ResponseDTO
+// @Failure 400 {object} common.ResponseDTO
+// @Failure 409 {object} common.ResponseDTO
+// @Router /api/v1/users/{id}/exports [POST]
+func (e exportApi) PostForUser(context echo.Context) error {
+	userFromToken, err := GetUserTokenDtoFromBearerToken(context, v1.Jwt{})
+	if err != nil {
+		return common.GenerateErrorResponse(context, err.Error(), "Operation Failed!")
+	}
+	if !isAdmin(userFromToken) {
+		return common.GenerateForbiddenResponse(context, "[ERROR]: Insufficient permission", "Operation Failed!")
+	}
+	user := v1.User{}.GetByID(context.Param("id"))
+	if user.ID == "" || user.Status == enums.DELETED {
+		return common.GenerateErrorResponse(context, "[ERROR]: User not found!", "Please provide a valid user id!")
+	}
+	return requestDataExport(context, user, userFromToken.ID)
+}
+
+// GetForUser... Get For User Api
+// @Summary Get data exports of a user api
+// @Description Api for admins to follow the state of data exports of a user, latest first. Download links are only given to the user
+// @Tags Export
+// @Produce json
+// @Param Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param id path string true "user id"
+// @Param page query string false "page"
+// @Param limit query string false "limit"
+// @Success 200 {object} common.ResponseDTO{data=[]v1.DataExport{}}
+// @Forbidden 403 {object} common.ResponseDTO
+// @Failure 400 {object} common.ResponseDTO
+// @Router /api/v1/users/{id}/exports [GET]
+func (e exportApi) GetForUser(context echo.Context) error {
+	userFromToken, err := GetUserTokenDtoFromBearerToken(context, v1.Jwt{})
+	if err != nil {
+		return common.GenerateErrorResponse(context, err.Error(), "Operation Failed!")
+	}
+	if !isAdmin(userFromToken) {
+		return common.GenerateForbiddenResponse(context, "[ERROR]: Insufficient permission", "Operation Failed!")
+	}
+	pagination := getPagination(context)
+	data, total := v1.DataExport{}.GetByUserId(context.Param("id"), pagination)
+	metadata := getPaginationMetadataWithLinks(context, pagination, total, int64(len(data)), nil)
+	return common.GenerateSuccessResponse(context, data, &metadata, "Successful")
+}
+
+// Download... Download Api
+// @Summary Download data export api
+// @Description Api for downloading the zip archive of a data export with the signed link of the export. The link stops working once the export expires
+// @Tags Export
+// @Produce application/zip
+// @Param token query string true "download token"
+// @Success 200 {file} binary
+// @Failure 400 {object} common.ResponseDTO
+// @Router /api/v1/exports/download [GET]
+func (e exportApi) Download(context echo.Context) error {
+	export, err := v1.GetDownloadableDataExport(context.QueryParam("token"))
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: Export is not found!", err.Error())
+	}
+	data, err := v1.NewBlobStore().Get(v1.DataExportBlobKey(export.ID))
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: Export is not found!", err.Error())
+	}
+	context.Response().Header().Set(echo.HeaderContentDisposition, "attachment; filename=\""+v1.DataExportFileName(export)+"\"")
+	return context.Blob(http.StatusOK, "application/zip", data)
+}
+
+// requestDataExport stores a pending export of the user and builds it in the background.
+func requestDataExport(context echo.Context, user v1.User, requestedBy string) error {
+	export := v1.DataExport{
+		ID:          uuid.New().String(),
+		UserId:      user.ID,
+		RequestedBy: requestedBy,
+		Status:      enums.EXPORT_PENDING,
+		CreatedAt:   time.Now().UTC(),
+	}
+	err := v1.DataExport{}.Store(export)
+	if err == v1.ErrDataExportInProgress {
+		return common.GenerateConflictResponse(context, "[ERROR]: "+err.Error(), "Operation Failed!")
+	}
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: Failed to request export!", err.Error())
+	}
+	go runDataExport(export, user)
+	return common.GenerateSuccessResponse(context, export, nil, "Operation Successful")
+}
+
+// runDataExport builds the archive of the export, stores it in the blob store and mails the user a
+// download link.
+func runDataExport(export v1.DataExport, user v1.User) {
+	store := v1.NewBlobStore()
+	if err := (v1.DataExport{}).UpdateStatus(export.ID, enums.EXPORT_RUNNING, ""); err != nil {
+		return
+	}
+	fail := func(err error) {
+		log.Println("[ERROR] Failed to build export:", err.Error())
+		v1.DataExport{}.UpdateStatus(export.ID, enums.EXPORT_FAILED, err.Error())
+	}
+	archive, err := v1.BuildDataExportArchive(user)
+	if err != nil {
+		fail(err)
+		return
+	}
+	if err := store.Put(v1.DataExportBlobKey(export.ID), archive); err != nil {
+		fail(err)
+		return
+	}
+	completedAt := time.Now().UTC()
+	expiresAt := completedAt.Add(time.Duration(config.DataExportLifetimeHours) * time.Hour)
+	if err := (v1.DataExport{}).Complete(export.ID, int64(len(archive)), completedAt, expiresAt); err != nil {
+		fail(err)
+		return
+	}
+	export.Status, export.CompletedAt, export.ExpiresAt = enums.EXPORT_COMPLETED, &completedAt, &expiresAt
+	export, err = export.WithDownloadUrl()
+	if err != nil {
+		log.Println("[ERROR] Failed to sign download link:", err.Error())
+		return
+	}
+	err = v1.NewMailer().Send(v1.Mail{
+		To:      user.Email,
+		Subject: "Your data export is ready",
+		Body: "Hi " + user.FirstName + ",\n\nYour data export is ready. Download it by opening the link below. The link expires in " +
+			strconv.FormatInt(config.DataExportLifetimeHours, 10) + " hours.\n\n" + export.DownloadUrl + "\n",
+	})
+	if err != nil {
+		log.Println("[ERROR] Failed to send export mail:", err.Error())
+	}
+}
@@ -0,0 +1,166 @@
+package v1
+
+import (
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/niloydeb1/Golang-Movie_API/api/common"
+	"github.com/niloydeb1/Golang-Movie_API/enums"
+	v1 "github.com/niloydeb1/Golang-Movie_API/src/v1"
+	"log"
+	"strconv"
+	"time"
+)
+
+// feedMaxLimit is the maximum number of activities of a feed page.
+const feedMaxLimit = 100
+
+func FeedRouter(g *echo.Group) {
+	g.GET("", feedApi{}.Get)
+}
+
+type feedApi struct {
+}
+
+// Get... Get Api
+// @Summary Get own feed api
+// @Description Api for getting activities of followed users, latest first. Pass next_cursor of the metadata as cursor to get the next page
+// @Tags Feed
+// @Produce json
+// @Param Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param cursor query string false "cursor of the page"
+// @Param limit query string false "limit, at most 100"
+// @Success 200 {object} common.ResponseDTO{data=[]v1.FeedEntry{}}
+// @Forbidden 403 {object} common.ResponseDTO
+// @Failure 400 {object} common.ResponseDTO
+// @Router /api/v1/feed [GET]
+func (f feedApi) Get(context echo.Context) error {
+	userFromToken, err := GetUserTokenDtoFromBearerToken(context, v1.Jwt{})
+	if err != nil {
+		return common.GenerateErrorResponse(context, err.Error(), "Operation Failed!")
+	}
+	var cursor *v1.FeedCursor
+	if value := context.QueryParam("cursor"); value != "" {
+		decoded, err := v1.DecodeFeedCursor(value)
+		if err != nil {
+			return common.GenerateErrorResponse(context, "[ERROR]: Invalid cursor is provided", err.Error())
+		}
+		cursor = &decoded
+	}
+	limit := int64(20)
+	if value := context.QueryParam("limit"); value != "" {
+		limit, err = strconv.ParseInt(value, 10, 64)
+		if err != nil || limit < 1 || limit > feedMaxLimit {
+			return common.GenerateErrorResponse(context, "[ERROR]: Invalid limit is provided", "limit must be between 1 and "+strconv.Itoa(feedMaxLimit))
+		}
+	}
+	activities, next := v1.FeedItem{}.GetFeed(userFromToken.ID, cursor, limit, v1.UserRelation{}.HiddenUserIds(userFromToken.ID))
+	actorIds := make([]string, 0, len(activities))
+	for _, activity := range activities {
+		actorIds = append(actorIds, activity.ActorId)
+	}
+	actors := v1.User{}.GetByIDs(actorIds)
+	data := make([]v1.FeedEntry, 0, len(activities))
+	for _, activity := range activities {
+		actor, ok := actors[activity.ActorId]
+		if !ok || actor.Status != enums.ACTIVE {
+			continue
+		}
+		data = append(data, v1.FeedEntry{Activity: activity, Actor: v1.NewUserPublicView(actor)})
+	}
+	metadata := common.MetaData{PerPage: limit, Links: []map[string]string{}}
+	if next != nil {
+		metadata.NextCursor = next.Encode()
+		metadata.Links = append(metadata.Links, map[string]string{"next": "/api/v1/feed?cursor=" + metadata.NextCursor + "&limit=" + strconv.FormatInt(limit, 10)})
+	}
+	return common.GenerateSuccessResponse(context, data, &metadata, "Successful")
+}
+
+// publishActivity delivers the activity to feeds of followers of the actor in the background. If
+// the target was hidden or deleted while it was delivered, the activity is retracted again.
+func publishActivity(actorId string, activityType enums.ACTIVITY_TYPE, targetId, movieTitle, summary string) {
+	activity := v1.Activity{
+		ID:         uuid.New().String(),
+		ActorId:    actorId,
+		Type:       activityType,
+		TargetId:   targetId,
+		MovieTitle: movieTitle,
+		Summary:    summary,
+		CreatedAt:  time.Now().UTC(),
+	}
+	go func() {
+		if err := (v1.Activity{}).Publish(activity); err != nil {
+			log.Println("[ERROR] Failed to publish activity:", err.Error())
+		}
+		if !isActivityTargetPublished(activity) {
+			retractActivity(activity.Type, activity.TargetId)
+		}
+	}()
+}
+
+// isActivityTargetPublished returns true if the target of the activity still exists and is visible
+// to followers of the actor.
+func isActivityTargetPublished(activity v1.Activity) bool {
+	switch activity.Type {
+	case enums.REVIEW_POSTED:
+		review := v1.Review{}.GetByID(activity.TargetId)
+		return review.ID != "" && review.Moderation.IsVisible()
+	case enums.COMMENT_POSTED:
+		comment := v1.Comment{}.GetByID(activity.TargetId)
+		return comment.ID != "" && !comment.Deleted && comment.Moderation.IsVisible()
+	case enums.DIARY_LOGGED:
+		return v1.DiaryEntry{}.GetByID(activity.ActorId, activity.TargetId).ID != ""
+	case enums.LIST_CREATED:
+		return v1.MovieList{}.GetByID(activity.TargetId).Visibility == enums.PUBLIC_LIST
+	}
+	return true
+}
+
+// retractActivity removes activities of the target from every feed.
+func retractActivity(activityType enums.ACTIVITY_TYPE, targetId string) {
+	if err := (v1.Activity{}).DeleteByTarget(activityType, targetId); err != nil {
+		log.Println("[ERROR] Failed to retract activity:", err.Error())
+	}
+}
+
+// contentActivityType returns the activity type of posting the content type.
+func contentActivityType(contentType enums.CONTENT_TYPE) enums.ACTIVITY_TYPE {
+	if contentType == enums.COMMENT {
+		return enums.COMMENT_POSTED
+	}
+	return enums.REVIEW_POSTED
+}
+
+// publishReview publishes a visible review to feeds.
+func publishReview(review v1.Review) {
+	publishActivity(review.ReviewerId, enums.REVIEW_POSTED, review.ID, review.Movie.Title, "reviewed "+review.Movie.Title+": "+v1.MaskSpoilers(review.ReviewTitle))
+}
+
+// publishDiaryEntry publishes a logged viewing to feeds.
+func publishDiaryEntry(entry v1.DiaryEntry) {
+	summary := "watched " + entry.Movie.Title
+	if entry.Rewatch {
+		summary = "rewatched " + entry.Movie.Title
+	}
+	publishActivity(entry.UserId, enums.DIARY_LOGGED, entry.ID, entry.Movie.Title, summary)
+}
+
+// publishList publishes a public list to feeds.
+func publishList(list v1.MovieList) {
+	publishActivity(list.OwnerId, enums.LIST_CREATED, list.ID, "", "created the list "+list.Name)
+}
+
+// publishComment publishes a visible comment to feeds. The summary names what is commented on,
+// not the comment, so spoilers do not leak into feeds.
+func publishComment(comment v1.Comment) {
+	summary, movieTitle := "commented", ""
+	if comment.ReviewId != "" {
+		review := v1.Review{}.GetByID(comment.ReviewId)
+		summary, movieTitle = "commented on the review "+v1.MaskSpoilers(review.ReviewTitle), review.Movie.Title
+	} else if comment.ListId != "" {
+		summary = "commented on the list " + v1.MovieList{}.GetByID(comment.ListId).Name
+	} else if comment.MovieId != "" {
+		movieTitle = v1.Movie{}.GetByID(comment.MovieId).Title
+		summary = "commented on the discussion of " + movieTitle
+	}
+	publishActivity(comment.CommenterId, enums.COMMENT_POSTED, comment.ID, movieTitle, summary)
+}
@@ -0,0 +1,145 @@
+package v1
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/niloydeb1/Golang-Movie_API/api/common"
+	v1 "github.com/niloydeb1/Golang-Movie_API/src/v1"
+	"log"
+	"time"
+)
+
+type followApi struct {
+}
+
+// Follow... Follow Api
+// @Summary Follow user api
+// @Description Api for following a user. Recent activities of the user are added to own feed
+// @Tags Follow
+// @Produce json
+// @Param Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param id path string true "user id"
+// @Success 200 {object} common.ResponseDTO
+// @Forbidden 403 {object} common.ResponseDTO
+// @Failure 400 {object} common.ResponseDTO
+// @Failure 409 {object} common.ResponseDTO
+// @Router /api/v1/users/{id}/follow [POST]
+func (f followApi) Follow(context echo.Context) error {
+	userFromToken, err := GetUserTokenDtoFromBearerToken(context, v1.Jwt{})
+	if err != nil {
+		return common.GenerateErrorResponse(context, err.Error(), "Operation Failed!")
+	}
+	followee, err := getPublicUser(context)
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: User Not Found!", err.Error())
+	}
+	if followee.ID == userFromToken.ID {
+		return common.GenerateErrorResponse(context, "[ERROR]: Failed to follow user!", "you can not follow yourself")
+	}
+	if (v1.UserRelation{}).IsBlockedByAny(userFromToken.ID, followee.ID) {
+		return common.GenerateForbiddenResponse(context, "[ERROR]: Insufficient permission", "You are blocked by this user!")
+	}
+	if (v1.UserRelation{}).IsBlockedByAny(followee.ID, userFromToken.ID) {
+		return common.GenerateErrorResponse(context, "[ERROR]: Failed to follow user!", "you blocked this user, unblock them first")
+	}
+	err = v1.Follow{}.Store(v1.Follow{FollowerId: userFromToken.ID, FolloweeId: followee.ID, CreatedAt: time.Now().UTC()})
+	if err == v1.ErrAlreadyFollowing {
+		return common.GenerateConflictResponse(context, nil, "You already follow this user!")
+	}
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: Failed to follow user!", err.Error())
+	}
+	if err := (v1.Activity{}).Backfill(userFromToken.ID, followee.ID); err != nil {
+		log.Println("[ERROR] Failed to backfill feed:", err.Error())
+	}
+	return common.GenerateSuccessResponse(context, "[SUCCESS]: User is followed", nil, "Operation Successful")
+}
+
+// Unfollow... Unfollow Api
+// @Summary Unfollow user api
+// @Description Api for unfollowing a user. Activities of the user are removed from own feed
+// @Tags Follow
+// @Produce json
+// @Param Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param id path string true "user id"
+// @Success 200 {object} common.ResponseDTO
+// @Forbidden 403 {object} common.ResponseDTO
+// @Failure 400 {object} common.ResponseDTO
+// @Router /api/v1/users/{id}/follow [DELETE]
+func (f followApi) Unfollow(context echo.Context) error {
+	userFromToken, err := GetUserTokenDtoFromBearerToken(context, v1.Jwt{})
+	if err != nil {
+		return common.GenerateErrorResponse(context, err.Error(), "Operation Failed!")
+	}
+	followeeId := context.Param("id")
+	err = v1.Follow{}.Delete(userFromToken.ID, followeeId)
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: Failed to unfollow user!", err.Error())
+	}
+	if err := (v1.FeedItem{}).RemoveActor(userFromToken.ID, followeeId); err != nil {
+		log.Println("[ERROR] Failed to clean up feed:", err.Error())
+	}
+	return common.GenerateSuccessResponse(context, "[SUCCESS]: User is unfollowed", nil, "Operation Successful")
+}
+
+// GetFollowers... Get Followers Api
+// @Summary Get followers api
+// @Description Api for getting followers of a user, latest first
+// @Tags Follow
+// @Produce json
+// @Param id path string true "user id"
+// @Param page query string false "page"
+// @Param limit query string false "limit"
+// @Success 200 {object} common.ResponseDTO{data=[]v1.FollowView{}}
+// @Failure 400 {object} common.ResponseDTO
+// @Router /api/v1/users/{id}/followers [GET]
+func (f followApi) GetFollowers(context echo.Context) error {
+	user, err := getPublicUser(context)
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: User Not Found!", err.Error())
+	}
+	pagination := getPagination(context)
+	follows, total := v1.Follow{}.GetFollowers(user.ID, hiddenUserIds(context), pagination)
+	ids := make([]string, 0, len(follows))
+	for _, follow := range follows {
+		ids = append(ids, follow.FollowerId)
+	}
+	return f.respond(context, pagination, total, follows, ids)
+}
+
+// GetFollowing... Get Following Api
+// @Summary Get following api
+// @Description Api for getting users a user follows, latest first
+// @Tags Follow
+// @Produce json
+// @Param id path string true "user id"
+// @Param page query string false "page"
+// @Param limit query string false "limit"
+// @Success 200 {object} common.ResponseDTO{data=[]v1.FollowView{}}
+// @Failure 400 {object} common.ResponseDTO
+// @Router /api/v1/users/{id}/following [GET]
+func (f followApi) GetFollowing(context echo.Context) error {
+	user, err := getPublicUser(context)
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: User Not Found!", err.Error())
+	}
+	pagination := getPagination(context)
+	follows, total := v1.Follow{}.GetFollowing(user.ID, hiddenUserIds(context), pagination)
+	ids := make([]string, 0, len(follows))
+	for _, follow := range follows {
+		ids = append(ids, follow.FolloweeId)
+	}
+	return f.respond(context, pagination, total, follows, ids)
+}
+
+// respond returns public views of the users of the ids, which are the other side of the follows.
+func (f followApi) respond(context echo.Context, pagination v1.Pagination, total int64, follows []v1.Follow, ids []string) error {
+	users := v1.User{}.GetByIDs(ids)
+	data := make([]v1.FollowView, 0, len(follows))
+	for i, follow := range follows {
+		if user, ok := users[ids[i]]; ok {
+			data = append(data, v1.FollowView{User: v1.NewUserPublicView(user), FollowedAt: follow.CreatedAt})
+		}
+	}
+	metadata := getPaginationMetadataWithLinks(context, pagination, total, int64(len(follows)), nil)
+	return common.GenerateSuccessResponse(context, data, &metadata, "Successful")
+}
@@ -0,0 +1,490 @@
+package v1
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/niloydeb1/Golang-Movie_API/api/common"
+	"github.com/niloydeb1/Golang-Movie_API/enums"
+	v1 "github.com/niloydeb1/Golang-Movie_API/src/v1"
+	"go.mongodb.org/mongo-driver/bson"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+)
+
+func ListRouter(g *echo.Group) {
+	g.GET("", listApi{}.Search)
+	g.POST("", listApi{}.Post)
+	g.GET("/:id", listApi{}.GetByID)
+	g.PATCH("/:id", listApi{}.Patch)
+	g.DELETE("/:id", listApi{}.Delete)
+	g.POST("/:id/entries", listApi{}.PostEntry)
+	g.PATCH("/:id/entries/:movie_id", listApi{}.PatchEntry)
+	g.DELETE("/:id/entries/:movie_id", listApi{}.DeleteEntry)
+	g.PUT("/:id/order", listApi{}.PutOrder)
+	g.PUT("/:id/feature", listApi{}.Feature)
+	g.DELETE("/:id/feature", listApi{}.Unfeature)
+	g.GET("/:id/comments", listApi{}.GetComments)
+}
+
+type listApi struct {
+}
+
+// Search... Search Api
+// @Summary Search lists api
+// @Description Api for browsing and searching public movie lists. Owners searching their own lists also get unlisted and private ones
+// @Tags List
+// @Produce json
+// @Param Authorization header string false "Insert your access token to search own lists" default(Bearer <Add access token here>)
+// @Param q query string false "text contained in list name"
+// @Param owner_id query string false "owner user id"
+// @Param featured query string false "set true to get only featured lists"
+// @Param sort query string false "sort order [updated/created/featured]"
+// @Param page query string false "page"
+// @Param limit query string false "limit"
+// @Success 200 {object} common.ResponseDTO{data=[]v1.MovieList{}}
+// @Failure 400 {object} common.ResponseDTO
+// @Router /api/v1/lists [GET]
+func (l listApi) Search(context echo.Context) error {
+	sort, err := getListSort(context)
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: Invalid sort is provided", err.Error())
+	}
+	userFromToken, _ := getOptionalUserTokenDto(context)
+	search := strings.TrimSpace(context.QueryParam("q"))
+	featured := context.QueryParam("featured") == "true"
+	query := v1.MovieListQuery(search, context.QueryParam("owner_id"), userFromToken.ID, featured)
+	query = v1.ExcludeUsersQuery(query, "owner_id", v1.UserRelation{}.HiddenUserIds(userFromToken.ID))
+	pagination := getPagination(context)
+	data, total := v1.MovieList{}.Search(query, pagination, sort)
+	values := url.Values{"sort": {string(sort)}}
+	for _, key := range []string{"q", "owner_id", "featured"} {
+		if value := context.QueryParam(key); value != "" {
+			values.Set(key, value)
+		}
+	}
+	metadata := getPaginationMetadataWithLinks(context, pagination, total, int64(len(data)), values)
+	return common.GenerateSuccessResponse(context, data, &metadata, "Successful")
+}
+
+// Post... Post Api
+// @Summary Create list api
+// @Description Api for creating a movie list, public and unranked by default
+// @Tags List
+// @Produce json
+// @Param Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param data body v1.MovieListDto true "dto for creating a list"
+// @Success 200 {object} common.ResponseDTO{data=v1.MovieList{}}
+// @Forbidden 403 {object} common.ResponseDTO
+// @Failure 400 {object} common.ResponseDTO
+// @Router /api/v1/lists [POST]
+func (l listApi) Post(context echo.Context) error {
+	userFromToken, err := GetUserTokenDtoFromBearerToken(context, v1.Jwt{})
+	if err != nil {
+		return common.GenerateErrorResponse(context, err.Error(), "Operation Failed!")
+	}
+	formData := v1.MovieListDto{}
+	if err := context.Bind(&formData); err != nil {
+		log.Println("Input Error:", err.Error())
+		return common.GenerateErrorResponse(context, nil, "Failed to Bind Input!")
+	}
+	err = formData.Validate(true)
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: Invalid data provided", err.Error())
+	}
+	now := time.Now().UTC()
+	list := v1.MovieList{
+		ID:         uuid.New().String(),
+		OwnerId:    userFromToken.ID,
+		Visibility: enums.PUBLIC_LIST,
+		Entries:    []v1.MovieListEntry{},
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	list = applyMovieListDto(list, formData)
+	err = v1.MovieList{}.Store(list)
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: Failed to create list!", err.Error())
+	}
+	if list.Visibility == enums.PUBLIC_LIST {
+		publishList(list)
+	}
+	return common.GenerateSuccessResponse(context, list, nil, "Operation Successful")
+}
+
+// GetByID... GetByID Api
+// @Summary Get list api
+// @Description Api for getting a movie list with its entries. Private lists are visible only to their owner and admins
+// @Tags List
+// @Produce json
+// @Param Authorization header string false "Insert your access token to get own private list" default(Bearer <Add access token here>)
+// @Param id path string true "list id"
+// @Success 200 {object} common.ResponseDTO{data=v1.MovieList{}}
+// @Failure 400 {object} common.ResponseDTO
+// @Router /api/v1/lists/{id} [GET]
+func (l listApi) GetByID(context echo.Context) error {
+	list, err := getVisibleList(context)
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: List is not found!", err.Error())
+	}
+	return common.GenerateSuccessResponse(context, list.WithRanks(), nil, "Successful")
+}
+
+// Patch... Patch Api
+// @Summary Update list api
+// @Description Api for updating name, description, visibility or ranking of an own list. A list that is no longer public is unfeatured
+// @Tags List
+// @Produce json
+// @Param Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param id path string true "list id"
+// @Param data body v1.MovieListDto true "dto for updating a list, omitted fields are left unchanged"
+// @Success 200 {object} common.ResponseDTO{data=v1.MovieList{}}
+// @Forbidden 403 {object} common.ResponseDTO
+// @Failure 400 {object} common.ResponseDTO
+// @Router /api/v1/lists/{id} [PATCH]
+func (l listApi) Patch(context echo.Context) error {
+	list, err := getOwnedList(context)
+	if err != nil {
+		return common.GenerateForbiddenResponse(context, "[ERROR]: "+err.Error(), "Operation Failed!")
+	}
+	formData := v1.MovieListDto{}
+	if err := context.Bind(&formData); err != nil {
+		log.Println("Input Error:", err.Error())
+		return common.GenerateErrorResponse(context, nil, "Failed to Bind Input!")
+	}
+	err = formData.Validate(false)
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: Invalid data provided", err.Error())
+	}
+	edited := applyMovieListDto(list, formData)
+	if edited.Visibility != enums.PUBLIC_LIST {
+		edited.Featured, edited.FeaturedAt = false, nil
+	}
+	edited, err = updateList(list, edited)
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: Failed to update list!", err.Error())
+	}
+	if list.Visibility != enums.PUBLIC_LIST && edited.Visibility == enums.PUBLIC_LIST {
+		publishList(edited)
+	} else if list.Visibility == enums.PUBLIC_LIST && edited.Visibility != enums.PUBLIC_LIST {
+		retractActivity(enums.LIST_CREATED, list.ID)
+	}
+	return common.GenerateSuccessResponse(context, edited.WithRanks(), nil, "Operation Successful")
+}
+
+// Delete... Delete Api
+// @Summary Delete list api
+// @Description Api for deleting an own list, admins can delete any list
+// @Tags List
+// @Produce json
+// @Param Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param id path string true "list id"
+// @Success 200 {object} common.ResponseDTO
+// @Forbidden 403 {object} common.ResponseDTO
+// @Failure 400 {object} common.ResponseDTO
+// @Router /api/v1/lists/{id} [DELETE]
+func (l listApi) Delete(context echo.Context) error {
+	userFromToken, err := GetUserTokenDtoFromBearerToken(context, v1.Jwt{})
+	if err != nil {
+		return common.GenerateErrorResponse(context, err.Error(), "Operation Failed!")
+	}
+	list := v1.MovieList{}.GetByID(context.Param("id"))
+	if list.ID == "" {
+		return common.GenerateErrorResponse(context, "[ERROR]: List is not found!", "Please provide a valid list id!")
+	}
+	if list.OwnerId != userFromToken.ID && !isAdmin(userFromToken) {
+		return common.GenerateForbiddenResponse(context, "[ERROR]: Insufficient permission", "Operation Failed!")
+	}
+	err = v1.MovieList{}.Delete(list.ID)
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: Failed to delete list!", err.Error())
+	}
+	retractActivity(enums.LIST_CREATED, list.ID)
+	commentIds, err := v1.Comment{}.DeleteByListId(list.ID)
+	if err != nil {
+		log.Println("[ERROR] Failed to delete list comments:", err.Error())
+	}
+	for _, id := range commentIds {
+		retractActivity(enums.COMMENT_POSTED, id)
+	}
+	return common.GenerateSuccessResponse(context, "[SUCCESS]: List is deleted", nil, "Operation Successful")
+}
+
+// PostEntry... Post Entry Api
+// @Summary Add movie to list api
+// @Description Api for adding a movie to an own list by movie id or title, at the given position or at the end. A movie that is not stored yet is fetched by title
+// @Tags List
+// @Produce json
+// @Param Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param id path string true "list id"
+// @Param data body v1.MovieListEntryDto true "dto for adding a movie to a list"
+// @Success 200 {object} common.ResponseDTO{data=v1.MovieList{}}
+// @Forbidden 403 {object} common.ResponseDTO
+// @Failure 400 {object} common.ResponseDTO
+// @Router /api/v1/lists/{id}/entries [POST]
+func (l listApi) PostEntry(context echo.Context) error {
+	list, err := getOwnedList(context)
+	if err != nil {
+		return common.GenerateForbiddenResponse(context, "[ERROR]: "+err.Error(), "Operation Failed!")
+	}
+	formData := v1.MovieListEntryDto{}
+	if err := context.Bind(&formData); err != nil {
+		log.Println("Input Error:", err.Error())
+		return common.GenerateErrorResponse(context, nil, "Failed to Bind Input!")
+	}
+	err = formData.Validate()
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: Invalid data provided", err.Error())
+	}
+	var movie v1.Movie
+	if formData.MovieId != "" {
+		movie = v1.Movie{}.GetByID(formData.MovieId)
+		if movie.ID == "" {
+			err = errors.New("movie does not exist")
+		}
+	} else {
+		movie, err = getOrFetchMovie(formData.Title)
+	}
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: Movie is not found", err.Error())
+	}
+	edited, err := list.AddEntry(v1.MovieListEntry{
+		Movie:   v1.NewMovieSummary(movie),
+		Notes:   formData.Notes,
+		AddedAt: time.Now().UTC(),
+	}, formData.Position)
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: Failed to add movie to list!", err.Error())
+	}
+	return saveList(context, list, edited)
+}
+
+// PatchEntry... Patch Entry Api
+// @Summary Update list entry api
+// @Description Api for updating notes of a movie on an own list
+// @Tags List
+// @Produce json
+// @Param Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param id path string true "list id"
+// @Param movie_id path string true "movie id"
+// @Param data body v1.MovieListEntryDto true "dto with notes of the entry, other fields are ignored"
+// @Success 200 {object} common.ResponseDTO{data=v1.MovieList{}}
+// @Forbidden 403 {object} common.ResponseDTO
+// @Failure 400 {object} common.ResponseDTO
+// @Router /api/v1/lists/{id}/entries/{movie_id} [PATCH]
+func (l listApi) PatchEntry(context echo.Context) error {
+	list, err := getOwnedList(context)
+	if err != nil {
+		return common.GenerateForbiddenResponse(context, "[ERROR]: "+err.Error(), "Operation Failed!")
+	}
+	formData := v1.MovieListEntryDto{}
+	if err := context.Bind(&formData); err != nil {
+		log.Println("Input Error:", err.Error())
+		return common.GenerateErrorResponse(context, nil, "Failed to Bind Input!")
+	}
+	formData.MovieId, formData.Position = context.Param("movie_id"), 0
+	err = formData.Validate()
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: Invalid data provided", err.Error())
+	}
+	index := list.IndexOf(formData.MovieId)
+	if index < 0 {
+		return common.GenerateErrorResponse(context, "[ERROR]: Failed to update list entry!", v1.ErrMovieListEntryNotFound.Error())
+	}
+	edited := list
+	edited.Entries = append([]v1.MovieListEntry{}, list.Entries...)
+	edited.Entries[index].Notes = formData.Notes
+	return saveList(context, list, edited)
+}
+
+// DeleteEntry... Delete Entry Api
+// @Summary Remove movie from list api
+// @Description Api for removing a movie from an own list
+// @Tags List
+// @Produce json
+// @Param Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param id path string true "list id"
+// @Param movie_id path string true "movie id"
+// @Success 200 {object} common.ResponseDTO{data=v1.MovieList{}}
+// @Forbidden 403 {object} common.ResponseDTO
+// @Failure 400 {object} common.ResponseDTO
+// @Router /api/v1/lists/{id}/entries/{movie_id} [DELETE]
+func (l listApi) DeleteEntry(context echo.Context) error {
+	list, err := getOwnedList(context)
+	if err != nil {
+		return common.GenerateForbiddenResponse(context, "[ERROR]: "+err.Error(), "Operation Failed!")
+	}
+	edited, err := list.RemoveEntry(context.Param("movie_id"))
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: Failed to remove movie from list!", err.Error())
+	}
+	return saveList(context, list, edited)
+}
+
+// PutOrder... Put Order Api
+// @Summary Reorder list api
+// @Description Api for reordering movies of an own list. Every movie of the list must be given exactly once
+// @Tags List
+// @Produce json
+// @Param Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param id path string true "list id"
+// @Param data body v1.MovieListOrderDto true "dto with movie ids in their new order"
+// @Success 200 {object} common.ResponseDTO{data=v1.MovieList{}}
+// @Forbidden 403 {object} common.ResponseDTO
+// @Failure 400 {object} common.ResponseDTO
+// @Router /api/v1/lists/{id}/order [PUT]
+func (l listApi) PutOrder(context echo.Context) error {
+	list, err := getOwnedList(context)
+	if err != nil {
+		return common.GenerateForbiddenResponse(context, "[ERROR]: "+err.Error(), "Operation Failed!")
+	}
+	formData := v1.MovieListOrderDto{}
+	if err := context.Bind(&formData); err != nil {
+		log.Println("Input Error:", err.Error())
+		return common.GenerateErrorResponse(context, nil, "Failed to Bind Input!")
+	}
+	edited, err := list.Reorder(formData.MovieIds)
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: Invalid data provided", err.Error())
+	}
+	return saveList(context, list, edited)
+}
+
+// Feature... Feature Api
+// @Summary Feature list api
+// @Description Api for admins to feature a public list
+// @Tags List
+// @Produce json
+// @Param Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param id path string true "list id"
+// @Success 200 {object} common.ResponseDTO
+// @Forbidden 403 {object} common.ResponseDTO
+// @Failure 400 {object} common.ResponseDTO
+// @Router /api/v1/lists/{id}/feature [PUT]
+func (l listApi) Feature(context echo.Context) error {
+	return l.setFeatured(context, true)
+}
+
+// Unfeature... Unfeature Api
+// @Summary Unfeature list api
+// @Description Api for admins to unfeature a list
+// @Tags List
+// @Produce json
+// @Param Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param id path string true "list id"
+// @Success 200 {object} common.ResponseDTO
+// @Forbidden 403 {object} common.ResponseDTO
+// @Failure 400 {object} common.ResponseDTO
+// @Router /api/v1/lists/{id}/feature [DELETE]
+func (l listApi) Unfeature(context echo.Context) error {
+	return l.setFeatured(context, false)
+}
+
+func (l listApi) setFeatured(context echo.Context, featured bool) error {
+	userFromToken, err := GetUserTokenDtoFromBearerToken(context, v1.Jwt{})
+	if err != nil {
+		return common.GenerateErrorResponse(context, err.Error(), "Operation Failed!")
+	}
+	if !isAdmin(userFromToken) {
+		return common.GenerateForbiddenResponse(context, "[ERROR]: Insufficient permission", "Operation Failed!")
+	}
+	list := v1.MovieList{}.GetByID(context.Param("id"))
+	if list.ID == "" {
+		return common.GenerateErrorResponse(context, "[ERROR]: List is not found!", "Please provide a valid list id!")
+	}
+	if featured && list.Visibility != enums.PUBLIC_LIST {
+		return common.GenerateErrorResponse(context, "[ERROR]: Failed to feature list!", "only public lists can be featured")
+	}
+	err = v1.MovieList{}.SetFeatured(list.ID, featured)
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: Failed to update list!", err.Error())
+	}
+	return common.GenerateSuccessResponse(context, nil, nil, "Operation Successful")
+}
+
+// GetComments... Get Comments Api
+// @Summary Get comments of list api
+// @Description Api for getting comments of a movie list
+// @Tags List
+// @Produce json
+// @Param id path string true "list id"
+// @Param sort query string false "sort order [oldest/newest]"
+// @Param view query string false "[tree] for nested replies or [flat] for threads flattened with depth, paginated by top level comments"
+// @Param show_spoilers query string false "set true to show spoiler content"
+// @Param page query string false "page"
+// @Param limit query string false "limit"
+// @Success 200 {object} common.ResponseDTO{data=[]v1.Comment{}}
+// @Failure 400 {object} common.ResponseDTO
+// @Router /api/v1/lists/{id}/comments [GET]
+func (l listApi) GetComments(context echo.Context) error {
+	list, err := getVisibleList(context)
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: List is not found!", err.Error())
+	}
+	return listComments(context, bson.M{"list_id": list.ID})
+}
+
+// getVisibleList returns the list of the id path param if the requesting user can see it.
+func getVisibleList(context echo.Context) (v1.MovieList, error) {
+	list := v1.MovieList{}.GetByID(context.Param("id"))
+	if list.ID == "" {
+		return v1.MovieList{}, errors.New("please provide a valid list id")
+	}
+	userFromToken, _ := getOptionalUserTokenDto(context)
+	if !list.IsVisibleTo(userFromToken.ID) && !isAdmin(userFromToken) {
+		return v1.MovieList{}, errors.New("please provide a valid list id")
+	}
+	return list, nil
+}
+
+// getOwnedList returns the list of the id path param if it belongs to the requesting user.
+func getOwnedList(context echo.Context) (v1.MovieList, error) {
+	userFromToken, err := GetUserTokenDtoFromBearerToken(context, v1.Jwt{})
+	if err != nil {
+		return v1.MovieList{}, err
+	}
+	list := v1.MovieList{}.GetByID(context.Param("id"))
+	if list.ID == "" || list.OwnerId != userFromToken.ID {
+		return v1.MovieList{}, errors.New("list is not found or does not belong to you")
+	}
+	return list, nil
+}
+
+// applyMovieListDto returns the list with the provided fields of the dto set.
+func applyMovieListDto(list v1.MovieList, dto v1.MovieListDto) v1.MovieList {
+	if dto.Name != nil {
+		list.Name = strings.TrimSpace(*dto.Name)
+	}
+	if dto.Description != nil {
+		list.Description = *dto.Description
+	}
+	if dto.Visibility != nil {
+		list.Visibility = *dto.Visibility
+	}
+	if dto.Ranked != nil {
+		list.Ranked = *dto.Ranked
+	}
+	return list
+}
+
+// updateList stores the edited list if it was not changed since the list was read.
+func updateList(list, edited v1.MovieList) (v1.MovieList, error) {
+	edited.UpdatedAt = time.Now().UTC()
+	err := v1.MovieList{}.Update(edited, list.UpdatedAt)
+	if err != nil {
+		return v1.MovieList{}, err
+	}
+	edited.EntryCount = int64(len(edited.Entries))
+	return edited, nil
+}
+
+// saveList stores the edited list and responds with it.
+func saveList(context echo.Context, list, edited v1.MovieList) error {
+	edited, err := updateList(list, edited)
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: Failed to update list!", err.Error())
+	}
+	return common.GenerateSuccessResponse(context, edited.WithRanks(), nil, "Operation Successful")
+}
@@ -0,0 +1,325 @@
+package v1
+
+import (
+	"errors"
+	"github.com/labstack/echo/v4"
+	"github.com/niloydeb1/Golang-Movie_API/api/common"
+	"github.com/niloydeb1/Golang-Movie_API/enums"
+	v1 "github.com/niloydeb1/Golang-Movie_API/src/v1"
+	"go.mongodb.org/mongo-driver/bson"
+	"log"
+	"net/url"
+	"time"
+)
+
+// ModerationRouter api/v1/moderation/* router
+func ModerationRouter(g *echo.Group) {
+	g.GET("", moderationApi{}.GetQueue)
+	g.GET("/mine", moderationApi{}.GetMine)
+	g.GET("/filter-decisions", moderationApi{}.GetFilterDecisions)
+	g.GET("/reports", moderationApi{}.GetReports)
+	g.GET("/reports/:type/:id", moderationApi{}.GetReportsByTarget)
+	g.PUT("/:type/:id", moderationApi{}.Moderate)
+}
+
+type moderationApi struct {
+}
+
+// GetQueue... Get Queue Api
+// @Summary Moderation queue api
+// @Description Api for admins to list reviews or comments waiting for moderation
+// @Tags Moderation
+// @Produce json
+// @Param Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param type query string false "content type [review/comment], review by default"
+// @Param state query string false "moderation state [pending/approved/rejected/hidden], pending by default"
+// @Param page query string false "page"
+// @Param limit query string false "limit"
+// @Success 200 {object} common.ResponseDTO{data=[]v1.Review{}}
+// @Forbidden 403 {object} common.ResponseDTO
+// @Failure 400 {object} common.ResponseDTO
+// @Router /api/v1/moderation [GET]
+func (m moderationApi) GetQueue(context echo.Context) error {
+	userFromToken, err := GetUserTokenDtoFromBearerToken(context, v1.Jwt{})
+	if err != nil {
+		return common.GenerateErrorResponse(context, err.Error(), "Operation Failed!")
+	}
+	if !isAdmin(userFromToken) {
+		return common.GenerateForbiddenResponse(context, "[ERROR]: Insufficient permission", "Operation Failed!")
+	}
+	contentType, err := getContentType(context.QueryParam("type"))
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: Invalid content type", err.Error())
+	}
+	state := enums.MODERATION_STATE(context.QueryParam("state"))
+	if state == "" {
+		state = enums.PENDING
+	}
+	if !v1.IsValidModerationState(state) {
+		return common.GenerateErrorResponse(context, "[ERROR]: Invalid moderation state", "state must be one of [pending/approved/rejected/hidden]")
+	}
+	query := bson.M{"moderation.state": state}
+	pagination := getPagination(context)
+	var data interface{}
+	var count, total int64
+	if contentType == enums.REVIEW {
+		reviews, reviewTotal := v1.Review{}.Search(query, pagination, enums.NEWEST)
+		data, count, total = reviews, int64(len(reviews)), reviewTotal
+	} else {
+		comments, commentTotal := v1.Comment{}.Search(query, pagination, enums.OLDEST_FIRST)
+		data, count, total = comments, int64(len(comments)), commentTotal
+	}
+	metadata := getPaginationMetadataWithLinks(context, pagination, total, count, url.Values{"type": {string(contentType)}, "state": {string(state)}})
+	return common.GenerateSuccessResponse(context, data,
+		&metadata, "Successful")
+}
+
+// GetMine... Get Mine Api
+// @Summary Own moderated content api
+// @Description Api for users to list their own reviews or comments that are pending, rejected or hidden
+// @Tags Moderation
+// @Produce json
+// @Param Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param type query string false "content type [review/comment], review by default"
+// @Param page query string false "page"
+// @Param limit query string false "limit"
+// @Success 200 {object} common.ResponseDTO{data=[]v1.Review{}}
+// @Forbidden 403 {object} common.ResponseDTO
+// @Failure 400 {object} common.ResponseDTO
+// @Router /api/v1/moderation/mine [GET]
+func (m moderationApi) GetMine(context echo.Context) error {
+	userFromToken, err := GetUserTokenDtoFromBearerToken(context, v1.Jwt{})
+	if err != nil {
+		return common.GenerateErrorResponse(context, err.Error(), "Operation Failed!")
+	}
+	if userFromToken.ID == "" {
+		return common.GenerateUnauthorizedResponse(context, "[ERROR]: User not found!", "Please provide valid user information.")
+	}
+	contentType, err := getContentType(context.QueryParam("type"))
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: Invalid content type", err.Error())
+	}
+	moderated := bson.M{"moderation.state": bson.M{"$in": []enums.MODERATION_STATE{enums.PENDING, enums.REJECTED, enums.HIDDEN}}}
+	pagination := getPagination(context)
+	var data interface{}
+	var count, total int64
+	if contentType == enums.REVIEW {
+		query := bson.M{"$and": []bson.M{{"reviewer_id": userFromToken.ID}, moderated}}
+		reviews, reviewTotal := v1.Review{}.Search(query, pagination, enums.NEWEST)
+		data, count, total = reviews, int64(len(reviews)), reviewTotal
+	} else {
+		query := bson.M{"$and": []bson.M{{"commenter_id": userFromToken.ID}, moderated}}
+		comments, commentTotal := v1.Comment{}.Search(query, pagination, enums.OLDEST_FIRST)
+		data, count, total = comments, int64(len(comments)), commentTotal
+	}
+	metadata := getPaginationMetadataWithLinks(context, pagination, total, count, url.Values{"type": {string(contentType)}})
+	return common.GenerateSuccessResponse(context, data,
+		&metadata, "Successful")
+}
+
+// GetFilterDecisions... Get Filter Decisions Api
+// @Summary Content filter decisions api
+// @Description Api for admins to list decisions content filters took on reviews and comments
+// @Tags Moderation
+// @Produce json
+// @Param Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param type query string false "content type [review/comment]"
+// @Param action query string false "filter action [reject/flag/mask]"
+// @Param author_id query string false "author id"
+// @Param page query string false "page"
+// @Param limit query string false "limit"
+// @Success 200 {object} common.ResponseDTO{data=[]v1.FilterDecision{}}
+// @Forbidden 403 {object} common.ResponseDTO
+// @Failure 400 {object} common.ResponseDTO
+// @Router /api/v1/moderation/filter-decisions [GET]
+func (m moderationApi) GetFilterDecisions(context echo.Context) error {
+	userFromToken, err := GetUserTokenDtoFromBearerToken(context, v1.Jwt{})
+	if err != nil {
+		return common.GenerateErrorResponse(context, err.Error(), "Operation Failed!")
+	}
+	if !isAdmin(userFromToken) {
+		return common.GenerateForbiddenResponse(context, "[ERROR]: Insufficient permission", "Operation Failed!")
+	}
+	query := bson.M{}
+	values := url.Values{}
+	for _, key := range []string{"type", "action", "author_id"} {
+		if value := context.QueryParam(key); value != "" {
+			values.Set(key, value)
+		}
+	}
+	if values.Get("type") != "" {
+		query["content_type"] = values.Get("type")
+	}
+	if values.Get("action") != "" {
+		query["action"] = values.Get("action")
+	}
+	if values.Get("author_id") != "" {
+		query["author_id"] = values.Get("author_id")
+	}
+	pagination := getPagination(context)
+	data, total := v1.FilterDecision{}.Search(query, pagination)
+	metadata := getPaginationMetadataWithLinks(context, pagination, total, int64(len(data)), values)
+	return common.GenerateSuccessResponse(context, data,
+		&metadata, "Successful")
+}
+
+// GetReports... Get Reports Api
+// @Summary Reported content api
+// @Description Api for admins to list reports grouped by reported review or comment, most reported first
+// @Tags Moderation
+// @Produce json
+// @Param Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param type query string false "content type [review/comment]"
+// @Param page query string false "page"
+// @Param limit query string false "limit"
+// @Success 200 {object} common.ResponseDTO{data=[]v1.ReportGroup{}}
+// @Forbidden 403 {object} common.ResponseDTO
+// @Failure 400 {object} common.ResponseDTO
+// @Router /api/v1/moderation/reports [GET]
+func (m moderationApi) GetReports(context echo.Context) error {
+	userFromToken, err := GetUserTokenDtoFromBearerToken(context, v1.Jwt{})
+	if err != nil {
+		return common.GenerateErrorResponse(context, err.Error(), "Operation Failed!")
+	}
+	if !isAdmin(userFromToken) {
+		return common.GenerateForbiddenResponse(context, "[ERROR]: Insufficient permission", "Operation Failed!")
+	}
+	query := bson.M{}
+	values := url.Values{}
+	if context.QueryParam("type") != "" {
+		contentType, err := getContentType(context.QueryParam("type"))
+		if err != nil {
+			return common.GenerateErrorResponse(context, "[ERROR]: Invalid content type", err.Error())
+		}
+		query["content_type"] = contentType
+		values.Set("type", string(contentType))
+	}
+	pagination := getPagination(context)
+	data, total := v1.Report{}.GetGroupedByTarget(query, pagination)
+	metadata := getPaginationMetadataWithLinks(context, pagination, total, int64(len(data)), values)
+	return common.GenerateSuccessResponse(context, data,
+		&metadata, "Successful")
+}
+
+// GetReportsByTarget... Get Reports By Target Api
+// @Summary Reports of content api
+// @Description Api for admins to list the reports of a review or comment
+// @Tags Moderation
+// @Produce json
+// @Param Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param type path string true "content type [review/comment]"
+// @Param id path string true "review or comment id"
+// @Param page query string false "page"
+// @Param limit query string false "limit"
+// @Success 200 {object} common.ResponseDTO{data=[]v1.Report{}}
+// @Forbidden 403 {object} common.ResponseDTO
+// @Failure 400 {object} common.ResponseDTO
+// @Router /api/v1/moderation/reports/{type}/{id} [GET]
+func (m moderationApi) GetReportsByTarget(context echo.Context) error {
+	userFromToken, err := GetUserTokenDtoFromBearerToken(context, v1.Jwt{})
+	if err != nil {
+		return common.GenerateErrorResponse(context, err.Error(), "Operation Failed!")
+	}
+	if !isAdmin(userFromToken) {
+		return common.GenerateForbiddenResponse(context, "[ERROR]: Insufficient permission", "Operation Failed!")
+	}
+	contentType, err := getContentType(context.Param("type"))
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: Invalid content type", err.Error())
+	}
+	pagination := getPagination(context)
+	data, total := v1.Report{}.GetByTarget(contentType, context.Param("id"), pagination)
+	metadata := getPaginationMetadataWithLinks(context, pagination, total, int64(len(data)), nil)
+	return common.GenerateSuccessResponse(context, data,
+		&metadata, "Successful")
+}
+
+// Moderate... Moderate Api
+// @Summary Moderate content api
+// @Description Api for admins to approve, reject or hide a review or comment
+// @Tags Moderation
+// @Produce json
+// @Param Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param type path string true "content type [review/comment]"
+// @Param id path string true "review or comment id"
+// @Param data body v1.ModerationDto true "dto for moderation decision"
+// @Success 200 {object} common.ResponseDTO{data=v1.Moderation{}}
+// @Forbidden 403 {object} common.ResponseDTO
+// @Failure 400 {object} common.ResponseDTO
+// @Router /api/v1/moderation/{type}/{id} [PUT]
+func (m moderationApi) Moderate(context echo.Context) error {
+	userFromToken, err := GetUserTokenDtoFromBearerToken(context, v1.Jwt{})
+	if err != nil {
+		return common.GenerateErrorResponse(context, err.Error(), "Operation Failed!")
+	}
+	if !isAdmin(userFromToken) {
+		return common.GenerateForbiddenResponse(context, "[ERROR]: Insufficient permission", "Operation Failed!")
+	}
+	contentType, err := getContentType(context.Param("type"))
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: Invalid content type", err.Error())
+	}
+	id := context.Param("id")
+	moderationDto := v1.ModerationDto{}
+	if err := context.Bind(&moderationDto); err != nil {
+		log.Println("Input Error:", err.Error())
+		return common.GenerateErrorResponse(context, nil, "Failed to Bind Input!")
+	}
+	err = moderationDto.Validate()
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: Invalid data provided", err.Error())
+	}
+	moderatedAt := time.Now().UTC()
+	moderation := v1.Moderation{
+		State:       moderationDto.State,
+		Reason:      moderationDto.Reason,
+		ModeratorId: userFromToken.ID,
+		ModeratedAt: &moderatedAt,
+	}
+	if contentType == enums.REVIEW {
+		review := v1.Review{}.GetByID(id)
+		if review.ID == "" {
+			return common.GenerateErrorResponse(context, "[ERROR]: Review is not found!", "Please provide a valid review id!")
+		}
+		err = v1.Review{}.UpdateModeration(id, moderation)
+		if err == nil {
+			notifyModeration(enums.REVIEW, id, review.ReviewerId, userFromToken.ID, moderation)
+			if !review.Moderation.IsVisible() && moderation.IsVisible() {
+				notifyMentions(enums.REVIEW, id, review.ReviewerId, review.ReviewerEmail, nil, review.Mentions)
+				publishReview(review)
+			} else if review.Moderation.IsVisible() && !moderation.IsVisible() {
+				retractActivity(enums.REVIEW_POSTED, id)
+			}
+		}
+	} else {
+		comment := v1.Comment{}.GetByID(id)
+		if comment.ID == "" {
+			return common.GenerateErrorResponse(context, "[ERROR]: Comment is not found!", "Please provide a valid comment id!")
+		}
+		err = v1.Comment{}.UpdateModeration(id, moderation)
+		if err == nil {
+			notifyModeration(enums.COMMENT, id, comment.CommenterId, userFromToken.ID, moderation)
+			if !comment.Moderation.IsVisible() && moderation.IsVisible() {
+				notifyComment(comment)
+				publishComment(comment)
+			} else if comment.Moderation.IsVisible() && !moderation.IsVisible() {
+				retractActivity(enums.COMMENT_POSTED, id)
+			}
+		}
+	}
+	if err != nil {
+		return common.GenerateErrorResponse(context, err, err.Error())
+	}
+	return common.GenerateSuccessResponse(context, moderation, nil, "Operation Successful")
+}
+
+// getContentType returns content type, review by default.
+func getContentType(contentType string) (enums.CONTENT_TYPE, error) {
+	switch enums.CONTENT_TYPE(contentType) {
+	case "", enums.REVIEW:
+		return enums.REVIEW, nil
+	case enums.COMMENT:
+		return enums.COMMENT, nil
+	}
+	return "", errors.New("type must be one of [review/comment]")
+}
@@ -2,12 +2,14 @@ package v1
 
 import (
 	"encoding/json"
+	"errors"
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	"github.com/niloydeb1/Golang-Movie_API/api/common"
 	v1 "github.com/niloydeb1/Golang-Movie_API/src/v1"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"net/url"
 	"strconv"
 	"strings"
 )
@@ -15,6 +17,8 @@ import (
 func MovieRouter(g *echo.Group) {
 	g.GET("/:id", movieApi{}.GetByID)
 	g.GET("", movieApi{}.Search)
+	g.GET("/:id/reviews", movieApi{}.GetReviews)
+	g.GET("/:id/discussions", movieApi{}.GetDiscussions)
 }
 
 type movieApi struct {
@@ -66,9 +70,9 @@ func (m movieApi) Search(context echo.Context) error {
 		}
 		data, total = v1.Movie{}.Search(query, v1.Pagination{})
 		if len(data) == 0 {
-			err := fetchAndStoreMovie(context, title)
+			_, err := fetchAndStoreMovie(title)
 			if err != nil {
-				return err
+				return common.GenerateErrorResponse(context, "[ERROR]: "+err.Error(), "Operation failed")
 			}
 		}
 		reg := ".*" + title + ".*"
@@ -93,27 +97,98 @@ func (m movieApi) Search(context echo.Context) error {
 		&metadata, "Successful")
 }
 
-func fetchAndStoreMovie(context echo.Context, title string) error {
+// GetReviews... Get Reviews Api
+// @Summary Get reviews of movie api
+// @Description Api for getting reviews of a movie
+// @Tags Movie
+// @Produce json
+// @Param id path string true "movie id"
+// @Param sort query string false "sort order [newest/helpful]"
+// @Param show_spoilers query string false "set true to show spoiler content"
+// @Param page query string false "page"
+// @Param limit query string false "limit"
+// @Success 200 {object} common.ResponseDTO{data=[]v1.Review{}}
+// @Forbidden 403 {object} common.ResponseDTO
+// @Failure 400 {object} common.ResponseDTO
+// @Router /api/v1/movies/{id}/reviews [GET]
+func (m movieApi) GetReviews(context echo.Context) error {
+	id := context.Param("id")
+	if id == "" {
+		return common.GenerateErrorResponse(context, "[ERROR]: Movie id is not provided", "Operation failed")
+	}
+	sort, err := getReviewSort(context)
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: Invalid sort is provided", err.Error())
+	}
+	pagination := getPagination(context)
+	query := bson.M{"movie.id": id}
+	data, total := v1.Review{}.Search(v1.VisibleQuery(v1.ExcludeUsersQuery(query, "reviewer_id", hiddenUserIds(context))), pagination, sort)
+	if !showSpoilers(context) {
+		for i := range data {
+			data[i] = data[i].MaskSpoilers()
+		}
+	}
+	metadata := getPaginationMetadataWithLinks(context, pagination, total, int64(len(data)), url.Values{"sort": {string(sort)}})
+	return common.GenerateSuccessResponse(context, data,
+		&metadata, "Successful")
+}
+
+// GetDiscussions... Get Discussions Api
+// @Summary Get discussions of movie api
+// @Description Api for getting discussion comments of a movie, that are not attached to a review
+// @Tags Movie
+// @Produce json
+// @Param id path string true "movie id"
+// @Param sort query string false "sort order [oldest/newest]"
+// @Param view query string false "[tree] for nested replies or [flat] for threads flattened with depth, paginated by top level comments"
+// @Param show_spoilers query string false "set true to show spoiler content"
+// @Param page query string false "page"
+// @Param limit query string false "limit"
+// @Success 200 {object} common.ResponseDTO{data=[]v1.Comment{}}
+// @Forbidden 403 {object} common.ResponseDTO
+// @Failure 400 {object} common.ResponseDTO
+// @Router /api/v1/movies/{id}/discussions [GET]
+func (m movieApi) GetDiscussions(context echo.Context) error {
+	id := context.Param("id")
+	if id == "" {
+		return common.GenerateErrorResponse(context, "[ERROR]: Movie id is not provided", "Operation failed")
+	}
+	return listComments(context, v1.DiscussionQuery(id))
+}
+
+// fetchAndStoreMovie fetches the movie of the title from Omdb and stores it if it is not stored
+// yet. It returns the stored movie.
+func fetchAndStoreMovie(title string) (v1.Movie, error) {
 	var movie v1.Movie
-	_, res, err := v1.HttpClientService{}.Get("https://www.omdbapi.com/?apikey=1154146a&t="+title, nil)
+	_, res, err := v1.HttpClientService{}.Get("https://www.omdbapi.com/?apikey=1154146a&t="+url.QueryEscape(title), nil)
 	if err != nil {
-		return common.GenerateErrorResponse(context, "[ERROR]: Failed to connect to Omdb server", "Operation failed")
+		return v1.Movie{}, errors.New("failed to connect to Omdb server")
 	}
 	err = json.Unmarshal(res, &movie)
 	if err != nil {
-		return common.GenerateErrorResponse(context, err, err.Error())
+		return v1.Movie{}, err
 	}
 	if movie.Title == "" {
-		return common.GenerateErrorResponse(context, "[ERROR]: Movie does not exist", "Operation failed")
+		return v1.Movie{}, errors.New("movie does not exist")
 	}
 	movie.Title = strings.ToLower(movie.Title)
 	checkMovie := v1.Movie{}.GetByTitle(movie.Title)
-	if checkMovie.Title == "" {
-		movie.ID = uuid.New().String()
-		err = v1.Movie{}.Store(movie)
-		if err != nil {
-			return common.GenerateErrorResponse(context, err, err.Error())
-		}
+	if checkMovie.Title != "" {
+		return checkMovie, nil
+	}
+	movie.ID = uuid.New().String()
+	err = v1.Movie{}.Store(movie)
+	if err != nil {
+		return v1.Movie{}, err
+	}
+	return movie, nil
+}
+
+// getOrFetchMovie returns the stored movie of the title, fetching it from Omdb if it is not stored yet.
+func getOrFetchMovie(title string) (v1.Movie, error) {
+	title = strings.ToLower(strings.TrimSpace(title))
+	if movie := (v1.Movie{}).GetByTitle(title); movie.ID != "" {
+		return movie, nil
 	}
-	return nil
+	return fetchAndStoreMovie(title)
 }
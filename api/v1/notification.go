@@ -0,0 +1,187 @@
+package v1
+
+import (
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/niloydeb1/Golang-Movie_API/api/common"
+	"github.com/niloydeb1/Golang-Movie_API/enums"
+	v1 "github.com/niloydeb1/Golang-Movie_API/src/v1"
+	"log"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// NotificationRouter api/v1/notifications/* router
+func NotificationRouter(g *echo.Group) {
+	g.GET("", notificationApi{}.Get)
+	g.GET("/unread-count", notificationApi{}.GetUnreadCount)
+	g.PUT("/read", notificationApi{}.MarkAllRead)
+	g.PUT("/:id/read", notificationApi{}.MarkRead)
+}
+
+type notificationApi struct {
+}
+
+// Get... Get Api
+// @Summary Get notifications api
+// @Description Api for listing own notifications, newest first
+// @Tags Notification
+// @Produce json
+// @Param Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param unread query string false "set true to list unread notifications only"
+// @Param page query string false "page"
+// @Param limit query string false "limit"
+// @Success 200 {object} common.ResponseDTO{data=[]v1.Notification{}}
+// @Forbidden 403 {object} common.ResponseDTO
+// @Failure 400 {object} common.ResponseDTO
+// @Router /api/v1/notifications [GET]
+func (n notificationApi) Get(context echo.Context) error {
+	userFromToken, err := GetUserTokenDtoFromBearerToken(context, v1.Jwt{})
+	if err != nil {
+		return common.GenerateErrorResponse(context, err.Error(), "Operation Failed!")
+	}
+	if userFromToken.ID == "" {
+		return common.GenerateUnauthorizedResponse(context, "[ERROR]: User not found!", "Please provide valid user information.")
+	}
+	unreadOnly := context.QueryParam("unread") == "true"
+	pagination := getPagination(context)
+	data, total := v1.Notification{}.GetByUserId(userFromToken.ID, unreadOnly, pagination)
+	values := url.Values{}
+	if unreadOnly {
+		values.Set("unread", "true")
+	}
+	metadata := getPaginationMetadataWithLinks(context, pagination, total, int64(len(data)), values)
+	return common.GenerateSuccessResponse(context, data,
+		&metadata, "Successful")
+}
+
+// GetUnreadCount... Get Unread Count Api
+// @Summary Unread notification count api
+// @Description Api for getting number of own unread notifications
+// @Tags Notification
+// @Produce json
+// @Param Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Success 200 {object} common.ResponseDTO{data=v1.UnreadNotificationCount{}}
+// @Forbidden 403 {object} common.ResponseDTO
+// @Failure 400 {object} common.ResponseDTO
+// @Router /api/v1/notifications/unread-count [GET]
+func (n notificationApi) GetUnreadCount(context echo.Context) error {
+	userFromToken, err := GetUserTokenDtoFromBearerToken(context, v1.Jwt{})
+	if err != nil {
+		return common.GenerateErrorResponse(context, err.Error(), "Operation Failed!")
+	}
+	if userFromToken.ID == "" {
+		return common.GenerateUnauthorizedResponse(context, "[ERROR]: User not found!", "Please provide valid user information.")
+	}
+	data := v1.UnreadNotificationCount{Unread: v1.Notification{}.CountUnread(userFromToken.ID)}
+	return common.GenerateSuccessResponse(context, data, nil, "Operation Successful")
+}
+
+// MarkRead... Mark Read Api
+// @Summary Mark notification read api
+// @Description Api for marking an own notification as read
+// @Tags Notification
+// @Produce json
+// @Param Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param id path string true "notification id"
+// @Success 200 {object} common.ResponseDTO
+// @Forbidden 403 {object} common.ResponseDTO
+// @Failure 400 {object} common.ResponseDTO
+// @Router /api/v1/notifications/{id}/read [PUT]
+func (n notificationApi) MarkRead(context echo.Context) error {
+	userFromToken, err := GetUserTokenDtoFromBearerToken(context, v1.Jwt{})
+	if err != nil {
+		return common.GenerateErrorResponse(context, err.Error(), "Operation Failed!")
+	}
+	if userFromToken.ID == "" {
+		return common.GenerateUnauthorizedResponse(context, "[ERROR]: User not found!", "Please provide valid user information.")
+	}
+	err = v1.Notification{}.MarkRead(context.Param("id"), userFromToken.ID)
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: Notification is not found!", err.Error())
+	}
+	return common.GenerateSuccessResponse(context, "[SUCCESS]: Notification is marked read", nil, "Operation Successful")
+}
+
+// MarkAllRead... Mark All Read Api
+// @Summary Mark all notifications read api
+// @Description Api for marking every own unread notification as read
+// @Tags Notification
+// @Produce json
+// @Param Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Success 200 {object} common.ResponseDTO{data=v1.UnreadNotificationCount{}}
+// @Forbidden 403 {object} common.ResponseDTO
+// @Failure 400 {object} common.ResponseDTO
+// @Router /api/v1/notifications/read [PUT]
+func (n notificationApi) MarkAllRead(context echo.Context) error {
+	userFromToken, err := GetUserTokenDtoFromBearerToken(context, v1.Jwt{})
+	if err != nil {
+		return common.GenerateErrorResponse(context, err.Error(), "Operation Failed!")
+	}
+	if userFromToken.ID == "" {
+		return common.GenerateUnauthorizedResponse(context, "[ERROR]: User not found!", "Please provide valid user information.")
+	}
+	marked, err := v1.Notification{}.MarkAllRead(userFromToken.ID)
+	if err != nil {
+		return common.GenerateErrorResponse(context, err, err.Error())
+	}
+	return common.GenerateSuccessResponse(context, "[SUCCESS]: "+strconv.FormatInt(marked, 10)+" notifications are marked read", nil, "Operation Successful")
+}
+
+// notify stores a notification for the user. Users are not notified of their own actions, nor of
+// actions of users they blocked or muted.
+func notify(userId, actorId string, notificationType enums.NOTIFICATION_TYPE, contentType enums.CONTENT_TYPE, targetId, message string) {
+	if userId == "" || userId == actorId {
+		return
+	}
+	if actorId != "" && (v1.UserRelation{}).IsSilencedBy(userId, actorId) {
+		return
+	}
+	err := v1.Notification{}.Store(v1.Notification{
+		ID:          uuid.New().String(),
+		UserId:      userId,
+		Type:        notificationType,
+		ActorId:     actorId,
+		ContentType: contentType,
+		TargetId:    targetId,
+		Message:     message,
+		CreatedAt:   time.Now().UTC(),
+	})
+	if err != nil {
+		log.Println("[ERROR] Failed to store notification:", err.Error())
+	}
+}
+
+// notifyMentions notifies users mentioned in a review or comment by its author. Users mentioned
+// before an edit are not notified again, and authors are not notified of mentioning themselves.
+func notifyMentions(contentType enums.CONTENT_TYPE, targetId, authorId, authorEmail string, previous, mentions []v1.Mention) {
+	for _, mention := range v1.NewMentions(previous, mentions) {
+		notify(mention.UserId, authorId, enums.MENTION, contentType, targetId, authorEmail+" mentioned you in a "+string(contentType))
+	}
+}
+
+// notifyComment notifies the author of the parent comment of a reply, or the author of the review
+// of a comment on it. Users mentioned in the comment are notified as well.
+func notifyComment(comment v1.Comment) {
+	if comment.ParentId != "" {
+		parent := v1.Comment{}.GetByID(comment.ParentId)
+		notify(parent.CommenterId, comment.CommenterId, enums.COMMENT_REPLY, enums.COMMENT, comment.ID, comment.CommenterEmail+" replied to your comment")
+	} else if comment.ReviewId != "" {
+		review := v1.Review{}.GetByID(comment.ReviewId)
+		notify(review.ReviewerId, comment.CommenterId, enums.REVIEW_COMMENT, enums.COMMENT, comment.ID, comment.CommenterEmail+" commented on your review "+review.ReviewTitle)
+	} else if comment.ListId != "" {
+		list := v1.MovieList{}.GetByID(comment.ListId)
+		notify(list.OwnerId, comment.CommenterId, enums.LIST_COMMENT, enums.COMMENT, comment.ID, comment.CommenterEmail+" commented on your list "+list.Name)
+	}
+	notifyMentions(enums.COMMENT, comment.ID, comment.CommenterId, comment.CommenterEmail, nil, comment.Mentions)
+}
+
+// notifyModeration notifies the author of a review or comment of a moderation decision on it.
+func notifyModeration(contentType enums.CONTENT_TYPE, targetId, authorId, actorId string, moderation v1.Moderation) {
+	message := "Your " + string(contentType) + " is " + string(moderation.State)
+	if moderation.Reason != "" {
+		message += ": " + moderation.Reason
+	}
+	notify(authorId, actorId, enums.MODERATION_DECISION, contentType, targetId, message)
+}
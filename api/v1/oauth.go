@@ -50,6 +50,9 @@ func (o oauthApi) handleRefreshTokenGrant(context echo.Context) error {
 	if !tokenValid {
 		return common.GenerateForbiddenResponse(context, "[ERROR]: Token is expired!", "Please login again to get token!")
 	}
+	if (v1.TokenService{}).GetByToken(refreshTokenDto.RefreshToken).Uid == "" {
+		return common.GenerateForbiddenResponse(context, "[ERROR]: Token is revoked!", "Please login again to get token!")
+	}
 	claims := jwt.MapClaims{}
 	jwt.ParseWithClaims(refreshTokenDto.RefreshToken, claims, func(token *jwt.Token) (interface{}, error) {
 		return []byte(config.Publickey), nil
@@ -71,13 +74,14 @@ func (o oauthApi) handleRefreshTokenGrant(context echo.Context) error {
 		log.Println(err.Error())
 		return common.GenerateForbiddenResponse(context, "[ERROR]: failed to read regular token lifetime from env!", err.Error())
 	}
-	token, refreshToken, err := v1.Jwt{}.GenerateToken(userFromToken.ID, tokenLifeTime, userFromToken)
+	userTokenDto := v1.NewUserTokenDto(existingUser)
+	token, refreshToken, err := v1.Jwt{}.GenerateToken(userTokenDto.ID, tokenLifeTime, userTokenDto)
 	if err != nil {
 		log.Println(err.Error())
 		return common.GenerateForbiddenResponse(context, "[ERROR]: failed to create token!", err.Error())
 	}
 
-	err = v1.TokenService{}.Store(v1.Token{Uid: userFromToken.ID, Token: token, RefreshToken: refreshToken})
+	err = v1.TokenService{}.Store(v1.Token{Uid: userTokenDto.ID, Token: token, RefreshToken: refreshToken})
 	if err != nil {
 		log.Println(err.Error())
 		return common.GenerateForbiddenResponse(context, "[ERROR]: failed to store token!", err.Error())
@@ -93,27 +97,22 @@ func (o oauthApi) handlePasswordGrant(context echo.Context) error {
 	}
 
 	existingUser := v1.User{}.GetByEmail(loginDto.Email)
-	if existingUser.ID == "" || existingUser.Status != enums.ACTIVE {
+	if existingUser.ID == "" || (existingUser.Status != enums.ACTIVE && existingUser.Status != enums.PENDING_VERIFICATION) {
 		return common.GenerateForbiddenResponse(context, "[ERROR]: No User found!", "Please login with actual user email!")
 	}
 	err := bcrypt.CompareHashAndPassword([]byte(existingUser.Password), []byte(loginDto.Password))
 	if err != nil {
 		return common.GenerateForbiddenResponse(context, "[ERROR]: Password not matched!", "Please login with due credential!"+err.Error())
 	}
+	if existingUser.Status == enums.PENDING_VERIFICATION {
+		return common.GenerateForbiddenResponse(context, "[ERROR]: Email is not verified!", "Please verify your email before login!")
+	}
 	tokenLifeTime, err := strconv.ParseInt(config.TokenLifetime, 10, 64)
 	if err != nil {
 		log.Println(err.Error())
 		return common.GenerateForbiddenResponse(context, "[ERROR]: failed to read regular token lifetime from env!", err.Error())
 	}
-	userTokenDto := v1.UserTokenDto{
-		ID:        existingUser.ID,
-		FirstName: existingUser.FirstName,
-		LastName:  existingUser.LastName,
-		Email:     existingUser.Email,
-		Phone:     existingUser.Phone,
-		Status:    existingUser.Status,
-		Role:      existingUser.Role,
-	}
+	userTokenDto := v1.NewUserTokenDto(existingUser)
 	token, refreshToken, err := v1.Jwt{}.GenerateToken(userTokenDto.ID, tokenLifeTime, userTokenDto)
 	if err != nil {
 		log.Println(err.Error())
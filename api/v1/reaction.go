@@ -0,0 +1,112 @@
+package v1
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/niloydeb1/Golang-Movie_API/api/common"
+	"github.com/niloydeb1/Golang-Movie_API/enums"
+	v1 "github.com/niloydeb1/Golang-Movie_API/src/v1"
+	"log"
+	"net/url"
+	"time"
+)
+
+// postReaction toggles a users reaction to a review or comment and updates its reaction counts.
+func postReaction(context echo.Context, contentType enums.CONTENT_TYPE) error {
+	userFromToken, err := GetUserTokenDtoFromBearerToken(context, v1.Jwt{})
+	if err != nil {
+		return common.GenerateErrorResponse(context, err.Error(), "Operation Failed!")
+	}
+	if userFromToken.ID == "" {
+		return common.GenerateUnauthorizedResponse(context, "[ERROR]: User not found!", "Please provide valid user information.")
+	}
+	id := context.Param("id")
+	authorId, errMsg := findReactionTarget(contentType, id)
+	if errMsg != "" {
+		return common.GenerateErrorResponse(context, errMsg, "Please provide a valid id!")
+	}
+	if (v1.UserRelation{}).IsBlockedByAny(userFromToken.ID, authorId) {
+		return common.GenerateForbiddenResponse(context, "[ERROR]: Insufficient permission", "You are blocked by the author!")
+	}
+	reactionDto := v1.ReactionDto{}
+	if err := context.Bind(&reactionDto); err != nil {
+		log.Println("Input Error:", err.Error())
+		return common.GenerateErrorResponse(context, nil, "Failed to Bind Input!")
+	}
+	err = reactionDto.Validate()
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: Invalid data provided", err.Error())
+	}
+	reacted, err := v1.Reaction{}.Toggle(v1.Reaction{
+		ContentType: contentType,
+		TargetId:    id,
+		UserId:      userFromToken.ID,
+		Emoji:       reactionDto.Emoji,
+		CreatedAt:   time.Now().UTC(),
+	})
+	if err != nil {
+		return common.GenerateErrorResponse(context, err, err.Error())
+	}
+	reactions := v1.Reaction{}.CountByTarget(contentType, id)
+	if contentType == enums.REVIEW {
+		err = v1.Review{}.UpdateReactions(id, reactions)
+	} else {
+		err = v1.Comment{}.UpdateReactions(id, reactions)
+	}
+	if err != nil {
+		return common.GenerateErrorResponse(context, err, err.Error())
+	}
+	return common.GenerateSuccessResponse(context, v1.ReactionResult{
+		Emoji:     reactionDto.Emoji,
+		Reacted:   reacted,
+		Reactions: reactions,
+	}, nil, "Operation Successful")
+}
+
+// getReactions responds with users who reacted to a review or comment.
+func getReactions(context echo.Context, contentType enums.CONTENT_TYPE) error {
+	id := context.Param("id")
+	if _, errMsg := findReactionTarget(contentType, id); errMsg != "" {
+		return common.GenerateErrorResponse(context, errMsg, "Please provide a valid id!")
+	}
+	emoji := context.QueryParam("emoji")
+	if emoji != "" && !v1.IsValidReactionEmoji(emoji) {
+		return common.GenerateErrorResponse(context, "[ERROR]: Invalid emoji is provided", "Operation Failed!")
+	}
+	pagination := getPagination(context)
+	reactions, total := v1.Reaction{}.GetByTarget(contentType, id, emoji, hiddenUserIds(context), pagination)
+	ids := make([]string, 0, len(reactions))
+	for _, reaction := range reactions {
+		ids = append(ids, reaction.UserId)
+	}
+	users := v1.User{}.GetByIDs(ids)
+	data := make([]v1.ReactionView, 0, len(reactions))
+	for _, reaction := range reactions {
+		if user, ok := users[reaction.UserId]; ok {
+			data = append(data, v1.ReactionView{User: v1.NewUserPublicView(user), Emoji: reaction.Emoji, CreatedAt: reaction.CreatedAt})
+		}
+	}
+	values := url.Values{}
+	if emoji != "" {
+		values.Set("emoji", emoji)
+	}
+	metadata := getPaginationMetadataWithLinks(context, pagination, total, int64(len(data)), values)
+	return common.GenerateSuccessResponse(context, data,
+		&metadata, "Successful")
+}
+
+// findReactionTarget returns the author of the review or comment, or an error message if it can not
+// be reacted to.
+func findReactionTarget(contentType enums.CONTENT_TYPE, id string) (string, string) {
+	if contentType == enums.REVIEW {
+		review := v1.Review{}.GetByID(id)
+		if review.ID == "" || !review.Moderation.IsVisible() {
+			return "", "[ERROR]: Review is not found!"
+		}
+		return review.ReviewerId, ""
+	}
+	comment := v1.Comment{}.GetByID(id)
+	if comment.ID == "" || comment.Deleted || !comment.Moderation.IsVisible() {
+		return "", "[ERROR]: Comment is not found!"
+	}
+	return comment.CommenterId, ""
+}
@@ -0,0 +1,193 @@
+package v1
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/niloydeb1/Golang-Movie_API/api/common"
+	"github.com/niloydeb1/Golang-Movie_API/enums"
+	v1 "github.com/niloydeb1/Golang-Movie_API/src/v1"
+	"log"
+	"time"
+)
+
+type relationApi struct {
+}
+
+// relationPastTense names each relation type in messages, e.g. "User is already muted!".
+var relationPastTense = map[enums.RELATION_TYPE]string{
+	enums.BLOCK: "blocked",
+	enums.MUTE:  "muted",
+}
+
+// Block... Block Api
+// @Summary Block user api
+// @Description Api for blocking a user. A blocked user can not comment on, mention, react to or follow the blocker, and content is hidden both ways. Follows between the users are removed
+// @Tags Relation
+// @Produce json
+// @Param Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param id path string true "user id"
+// @Success 200 {object} common.ResponseDTO
+// @Forbidden 403 {object} common.ResponseDTO
+// @Failure 400 {object} common.ResponseDTO
+// @Failure 409 {object} common.ResponseDTO
+// @Router /api/v1/users/{id}/block [POST]
+func (r relationApi) Block(context echo.Context) error {
+	return r.set(context, enums.BLOCK)
+}
+
+// Unblock... Unblock Api
+// @Summary Unblock user api
+// @Description Api for unblocking a user
+// @Tags Relation
+// @Produce json
+// @Param Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param id path string true "user id"
+// @Success 200 {object} common.ResponseDTO
+// @Forbidden 403 {object} common.ResponseDTO
+// @Failure 400 {object} common.ResponseDTO
+// @Router /api/v1/users/{id}/block [DELETE]
+func (r relationApi) Unblock(context echo.Context) error {
+	return r.unset(context, enums.BLOCK)
+}
+
+// Mute... Mute Api
+// @Summary Mute user api
+// @Description Api for muting a user. Content of a muted user is hidden from own feed and listings, and their notifications are not delivered
+// @Tags Relation
+// @Produce json
+// @Param Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param id path string true "user id"
+// @Success 200 {object} common.ResponseDTO
+// @Forbidden 403 {object} common.ResponseDTO
+// @Failure 400 {object} common.ResponseDTO
+// @Failure 409 {object} common.ResponseDTO
+// @Router /api/v1/users/{id}/mute [POST]
+func (r relationApi) Mute(context echo.Context) error {
+	return r.set(context, enums.MUTE)
+}
+
+// Unmute... Unmute Api
+// @Summary Unmute user api
+// @Description Api for unmuting a user
+// @Tags Relation
+// @Produce json
+// @Param Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param id path string true "user id"
+// @Success 200 {object} common.ResponseDTO
+// @Forbidden 403 {object} common.ResponseDTO
+// @Failure 400 {object} common.ResponseDTO
+// @Router /api/v1/users/{id}/mute [DELETE]
+func (r relationApi) Unmute(context echo.Context) error {
+	return r.unset(context, enums.MUTE)
+}
+
+// GetBlocks... Get Blocks Api
+// @Summary Get blocked users api
+// @Description Api for getting own blocked users, latest first
+// @Tags Relation
+// @Produce json
+// @Param Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param page query string false "page"
+// @Param limit query string false "limit"
+// @Success 200 {object} common.ResponseDTO{data=[]v1.UserRelationView{}}
+// @Forbidden 403 {object} common.ResponseDTO
+// @Failure 400 {object} common.ResponseDTO
+// @Router /api/v1/users/me/blocks [GET]
+func (r relationApi) GetBlocks(context echo.Context) error {
+	return r.list(context, enums.BLOCK)
+}
+
+// GetMutes... Get Mutes Api
+// @Summary Get muted users api
+// @Description Api for getting own muted users, latest first
+// @Tags Relation
+// @Produce json
+// @Param Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param page query string false "page"
+// @Param limit query string false "limit"
+// @Success 200 {object} common.ResponseDTO{data=[]v1.UserRelationView{}}
+// @Forbidden 403 {object} common.ResponseDTO
+// @Failure 400 {object} common.ResponseDTO
+// @Router /api/v1/users/me/mutes [GET]
+func (r relationApi) GetMutes(context echo.Context) error {
+	return r.list(context, enums.MUTE)
+}
+
+func (r relationApi) set(context echo.Context, relationType enums.RELATION_TYPE) error {
+	userFromToken, err := GetUserTokenDtoFromBearerToken(context, v1.Jwt{})
+	if err != nil {
+		return common.GenerateErrorResponse(context, err.Error(), "Operation Failed!")
+	}
+	target := v1.User{}.GetByID(context.Param("id"))
+	if target.ID == "" || target.Status == enums.DELETED {
+		return common.GenerateErrorResponse(context, "[ERROR]: User Not Found!", "Please give a valid user id!")
+	}
+	if target.ID == userFromToken.ID {
+		return common.GenerateErrorResponse(context, "[ERROR]: Failed to "+string(relationType)+" user!", "you can not "+string(relationType)+" yourself")
+	}
+	err = v1.UserRelation{}.Store(v1.UserRelation{
+		UserId:    userFromToken.ID,
+		TargetId:  target.ID,
+		Type:      relationType,
+		CreatedAt: time.Now().UTC(),
+	})
+	if err == v1.ErrRelationExists {
+		return common.GenerateConflictResponse(context, nil, "User is already "+relationPastTense[relationType]+"!")
+	}
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: Failed to "+string(relationType)+" user!", err.Error())
+	}
+	if relationType == enums.BLOCK {
+		unfollowBothWays(userFromToken.ID, target.ID)
+	} else if err := (v1.FeedItem{}).RemoveActor(userFromToken.ID, target.ID); err != nil {
+		log.Println("[ERROR] Failed to clean up feed:", err.Error())
+	}
+	return common.GenerateSuccessResponse(context, nil, nil, "Operation Successful")
+}
+
+func (r relationApi) unset(context echo.Context, relationType enums.RELATION_TYPE) error {
+	userFromToken, err := GetUserTokenDtoFromBearerToken(context, v1.Jwt{})
+	if err != nil {
+		return common.GenerateErrorResponse(context, err.Error(), "Operation Failed!")
+	}
+	err = v1.UserRelation{}.Delete(userFromToken.ID, context.Param("id"), relationType)
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: Failed to update user!", err.Error())
+	}
+	return common.GenerateSuccessResponse(context, nil, nil, "Operation Successful")
+}
+
+func (r relationApi) list(context echo.Context, relationType enums.RELATION_TYPE) error {
+	userFromToken, err := GetUserTokenDtoFromBearerToken(context, v1.Jwt{})
+	if err != nil {
+		return common.GenerateErrorResponse(context, err.Error(), "Operation Failed!")
+	}
+	pagination := getPagination(context)
+	relations, total := v1.UserRelation{}.GetByUserId(userFromToken.ID, relationType, pagination)
+	ids := make([]string, 0, len(relations))
+	for _, relation := range relations {
+		ids = append(ids, relation.TargetId)
+	}
+	users := v1.User{}.GetByIDs(ids)
+	data := make([]v1.UserRelationView, 0, len(relations))
+	for _, relation := range relations {
+		if user, ok := users[relation.TargetId]; ok {
+			data = append(data, v1.UserRelationView{User: v1.NewUserPublicView(user), CreatedAt: relation.CreatedAt})
+		}
+	}
+	metadata := getPaginationMetadataWithLinks(context, pagination, total, int64(len(data)), nil)
+	return common.GenerateSuccessResponse(context, data, &metadata, "Successful")
+}
+
+// unfollowBothWays removes follows between the users and their activities from each others feeds.
+func unfollowBothWays(userId, otherId string) {
+	for _, pair := range [][2]string{{userId, otherId}, {otherId, userId}} {
+		if (v1.Follow{}).IsFollowing(pair[0], pair[1]) {
+			if err := (v1.Follow{}).Delete(pair[0], pair[1]); err != nil {
+				log.Println("[ERROR] Failed to remove follow:", err.Error())
+			}
+		}
+		if err := (v1.FeedItem{}).RemoveActor(pair[0], pair[1]); err != nil {
+			log.Println("[ERROR] Failed to clean up feed:", err.Error())
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package v1
+
+import (
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/niloydeb1/Golang-Movie_API/api/common"
+	"github.com/niloydeb1/Golang-Movie_API/enums"
+	v1 "github.com/niloydeb1/Golang-Movie_API/src/v1"
+	"log"
+	"strconv"
+	"time"
+)
+
+// postReport stores a users report of a review or comment, and hides the content once it
+// received the configured number of distinct reports.
+func postReport(context echo.Context, contentType enums.CONTENT_TYPE) error {
+	userFromToken, err := GetUserTokenDtoFromBearerToken(context, v1.Jwt{})
+	if err != nil {
+		return common.GenerateErrorResponse(context, err.Error(), "Operation Failed!")
+	}
+	if userFromToken.ID == "" {
+		return common.GenerateUnauthorizedResponse(context, "[ERROR]: User not found!", "Please provide valid user information.")
+	}
+	id := context.Param("id")
+	var authorId string
+	var moderation v1.Moderation
+	if contentType == enums.REVIEW {
+		review := v1.Review{}.GetByID(id)
+		if review.ID == "" || !review.Moderation.IsVisible() {
+			return common.GenerateErrorResponse(context, "[ERROR]: Review is not found!", "Please provide a valid review id!")
+		}
+		authorId, moderation = review.ReviewerId, review.Moderation
+	} else {
+		comment := v1.Comment{}.GetByID(id)
+		if comment.ID == "" || !comment.Moderation.IsVisible() {
+			return common.GenerateErrorResponse(context, "[ERROR]: Comment is not found!", "Please provide a valid comment id!")
+		}
+		authorId, moderation = comment.CommenterId, comment.Moderation
+	}
+	if authorId == userFromToken.ID {
+		return common.GenerateForbiddenResponse(context, "[ERROR]: Insufficient permission", "You can not report your own content!")
+	}
+	reportDto := v1.ReportDto{}
+	if err := context.Bind(&reportDto); err != nil {
+		log.Println("Input Error:", err.Error())
+		return common.GenerateErrorResponse(context, nil, "Failed to Bind Input!")
+	}
+	err = reportDto.Validate()
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: Invalid data provided", err.Error())
+	}
+	err = v1.Report{}.Store(v1.Report{
+		ID:          uuid.New().String(),
+		ContentType: contentType,
+		TargetId:    id,
+		ReporterId:  userFromToken.ID,
+		Reason:      reportDto.Reason,
+		Details:     reportDto.Details,
+		CreatedAt:   time.Now().UTC(),
+	})
+	if err == v1.ErrAlreadyReported {
+		return common.GenerateConflictResponse(context, "[ERROR]: "+err.Error(), "Operation Failed!")
+	}
+	if err != nil {
+		return common.GenerateErrorResponse(context, err, err.Error())
+	}
+	reports := v1.Report{}.CountByTarget(contentType, id)
+	if v1.ShouldAutoHide(reports, moderation) {
+		moderation = v1.Moderation{
+			State:  enums.HIDDEN,
+			Reason: "automatically hidden after " + strconv.FormatInt(reports, 10) + " reports, pending review",
+		}
+		if contentType == enums.REVIEW {
+			err = v1.Review{}.UpdateModeration(id, moderation)
+		} else {
+			err = v1.Comment{}.UpdateModeration(id, moderation)
+		}
+		if err != nil {
+			log.Println("[ERROR] Failed to hide reported content:", err.Error())
+		} else {
+			notifyModeration(contentType, id, authorId, "", moderation)
+			retractActivity(contentActivityType(contentType), id)
+		}
+	}
+	return common.GenerateSuccessResponse(context, "[SUCCESS]: Report is submitted successfully", nil, "Operation Successful")
+}
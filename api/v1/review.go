@@ -1,6 +1,7 @@
 package v1
 
 import (
+	"errors"
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	"github.com/niloydeb1/Golang-Movie_API/api/common"
@@ -9,17 +10,27 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"log"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
 )
 
 func ReviewRouter(g *echo.Group) {
+	g.GET("/duplicates", reviewApi{}.GetDuplicates)
 	g.GET("/:id", reviewApi{}.GetByID)
 	g.GET("", reviewApi{}.Search)
 	g.GET("/:id/comments", reviewApi{}.GetComments)
+	g.GET("/:id/revisions", reviewApi{}.GetRevisions)
 	g.POST("", reviewApi{}.Post)
+	g.PUT("/:id", reviewApi{}.Put)
+	g.PATCH("/:id", reviewApi{}.Patch)
 	g.DELETE("/:id", reviewApi{}.Delete)
+	g.POST("/:id/votes", reviewApi{}.Vote)
+	g.DELETE("/:id/votes", reviewApi{}.RetractVote)
+	g.POST("/:id/reports", reviewApi{}.Report)
+	g.GET("/:id/reactions", reviewApi{}.GetReactions)
+	g.POST("/:id/reactions", reviewApi{}.React)
 }
 
 type reviewApi struct {
@@ -41,7 +52,7 @@ func (r reviewApi) GetByID(context echo.Context) error {
 		return common.GenerateErrorResponse(context, "[ERROR]: Review id is not provided", "Operation failed")
 	}
 	data := v1.Review{}.GetByID(id)
-	if data.ID == "" {
+	if data.ID == "" || (!data.Moderation.IsVisible() && !canSeeModeratedContent(context, data.ReviewerId)) {
 		return common.GenerateErrorResponse(context, "[ERROR]: Review is not found!", "Please provide a valid review id!")
 	}
 	return common.GenerateSuccessResponse(context, data, nil, "Operation Successful")
@@ -53,6 +64,8 @@ func (r reviewApi) GetByID(context echo.Context) error {
 // @Tags Review
 // @Produce json
 // @Param title query string false "movie title keyword"
+// @Param sort query string false "sort order [newest/helpful]"
+// @Param show_spoilers query string false "set true to show spoiler content"
 // @Param page query string false "page"
 // @Param limit query string false "limit"
 // @Success 200 {object} common.ResponseDTO{data=[]v1.Review{}}
@@ -61,6 +74,10 @@ func (r reviewApi) GetByID(context echo.Context) error {
 // @Router /api/v1/reviews [GET]
 func (r reviewApi) Search(context echo.Context) error {
 	pagination := getPagination(context)
+	sort, err := getReviewSort(context)
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: Invalid sort is provided", err.Error())
+	}
 	title := strings.ToLower(context.QueryParam("title"))
 	var query bson.M
 	var data []v1.Review
@@ -74,16 +91,13 @@ func (r reviewApi) Search(context echo.Context) error {
 			}},
 		}
 	}
-	data, total = v1.Review{}.Search(query, pagination)
-	metadata := common.GetPaginationMetadata(pagination.Page, pagination.Limit, total, int64(len(data)))
-	uri := strings.Split(context.Request().RequestURI, "?")[0]
-	if pagination.Page > 0 {
-		metadata.Links = append(metadata.Links, map[string]string{"prev": uri + "?title=" + context.QueryParam("title") + "&page=" + strconv.FormatInt(pagination.Page-1, 10) + "&limit=" + strconv.FormatInt(pagination.Limit, 10)})
-	}
-	metadata.Links = append(metadata.Links, map[string]string{"self": uri + "?title=" + context.QueryParam("title") + "&page=" + strconv.FormatInt(pagination.Page, 10) + "&limit=" + strconv.FormatInt(pagination.Limit, 10)})
-	if (pagination.Page+1)*pagination.Limit < metadata.TotalCount {
-		metadata.Links = append(metadata.Links, map[string]string{"next": uri + "?title=" + context.QueryParam("title") + "&page=" + strconv.FormatInt(pagination.Page+1, 10) + "&limit=" + strconv.FormatInt(pagination.Limit, 10)})
+	data, total = v1.Review{}.Search(v1.VisibleQuery(v1.ExcludeUsersQuery(query, "reviewer_id", hiddenUserIds(context))), pagination, sort)
+	if !showSpoilers(context) {
+		for i := range data {
+			data[i] = data[i].MaskSpoilers()
+		}
 	}
+	metadata := getPaginationMetadataWithLinks(context, pagination, total, int64(len(data)), url.Values{"title": {context.QueryParam("title")}, "sort": {string(sort)}})
 	return common.GenerateSuccessResponse(context, data,
 		&metadata, "Successful")
 }
@@ -94,6 +108,9 @@ func (r reviewApi) Search(context echo.Context) error {
 // @Tags Comment
 // @Produce json
 // @Param id path string true "review id"
+// @Param sort query string false "sort order [oldest/newest]"
+// @Param view query string false "[tree] for nested replies or [flat] for threads flattened with depth, paginated by top level comments"
+// @Param show_spoilers query string false "set true to show spoiler content"
 // @Param page query string false "page"
 // @Param limit query string false "limit"
 // @Success 200 {object} common.ResponseDTO{data=[]v1.Comment{}}
@@ -105,19 +122,7 @@ func (r reviewApi) GetComments(context echo.Context) error {
 	if id == "" {
 		return common.GenerateErrorResponse(context, "[ERROR]: Review id is not provided", "Operation failed")
 	}
-	pagination := getPagination(context)
-	data, total := v1.Comment{}.GetByReviewId(id, pagination)
-	metadata := common.GetPaginationMetadata(pagination.Page, pagination.Limit, total, int64(len(data)))
-	uri := strings.Split(context.Request().RequestURI, "?")[0]
-	if pagination.Page > 0 {
-		metadata.Links = append(metadata.Links, map[string]string{"prev": uri + "?page=" + strconv.FormatInt(pagination.Page-1, 10) + "&limit=" + strconv.FormatInt(pagination.Limit, 10)})
-	}
-	metadata.Links = append(metadata.Links, map[string]string{"self": uri + "?page=" + strconv.FormatInt(pagination.Page, 10) + "&limit=" + strconv.FormatInt(pagination.Limit, 10)})
-	if (pagination.Page+1)*pagination.Limit < metadata.TotalCount {
-		metadata.Links = append(metadata.Links, map[string]string{"next": uri + "?page=" + strconv.FormatInt(pagination.Page+1, 10) + "&limit=" + strconv.FormatInt(pagination.Limit, 10)})
-	}
-	return common.GenerateSuccessResponse(context, data,
-		&metadata, "Successful")
+	return listComments(context, bson.M{"review_id": id})
 }
 
 // Post... Post Api
@@ -127,9 +132,14 @@ func (r reviewApi) GetComments(context echo.Context) error {
 // @Produce json
 // @Param Authorization header string true "Insert your access token while posting review" default(Bearer <Add access token here>)
 // @Param data body v1.Review true "dto for posting review"
+// @Param upsert query string false "set true to replace the existing review of the movie"
+// @Param diary query string false "set true to log a viewing of the movie to own diary with a new review"
+// @Param watched_on query string false "YYYY-MM-DD date of the logged viewing, today by default"
+// @Param rewatch query string false "set true or false to override the derived rewatch flag of the logged viewing"
 // @Success 200 {object} common.ResponseDTO
 // @Failure 400 {object} common.ResponseDTO
 // @Forbidden 403 {object} common.ResponseDTO
+// @Failure 409 {object} common.ResponseDTO{data=v1.ReviewConflictDto{}}
 // @Router /api/v1/reviews [POST]
 func (r reviewApi) Post(context echo.Context) error {
 	userFromToken, err := GetUserTokenDtoFromBearerToken(context, v1.Jwt{})
@@ -152,6 +162,24 @@ func (r reviewApi) Post(context echo.Context) error {
 	if movie.ID == "" {
 		return common.GenerateErrorResponse(context, "[ERROR]: Movie is not found", "Operation Failed")
 	}
+	existing := v1.Review{}.GetByReviewerAndMovie(userFromToken.ID, movie.ID)
+	if existing.ID != "" {
+		if context.QueryParam("upsert") != "true" {
+			return common.GenerateConflictResponse(context, v1.NewReviewConflictDto(existing.ID), "You have already reviewed this movie!")
+		}
+		edited := existing
+		edited.ReviewTitle = reviewDto.ReviewTitle
+		edited.Description = reviewDto.Description
+		edited.Spoiler = reviewDto.Spoiler
+		return r.saveEdit(context, userFromToken.ID, existing, edited)
+	}
+	var diaryDto *v1.DiaryEntryDto
+	if context.QueryParam("diary") == "true" {
+		diaryDto, err = getReviewDiaryDto(context, reviewDto)
+		if err != nil {
+			return common.GenerateErrorResponse(context, "[ERROR]: Invalid diary data provided", err.Error())
+		}
+	}
 	reviewDto.Movie.Title = movie.Title
 	reviewDto.Movie.Year = movie.Year
 	reviewDto.Movie.Director = movie.Director
@@ -160,13 +188,230 @@ func (r reviewApi) Post(context echo.Context) error {
 	reviewDto.ReviewerEmail = userFromToken.Email
 	reviewDto.ReviewerId = userFromToken.ID
 	reviewDto.CreatedAt = time.Now().UTC()
+	reviewDto.EditedAt = nil
+	reviewDto.Helpful, reviewDto.Unhelpful, reviewDto.HelpfulScore = 0, 0, 0
+	reviewDto.Reactions = map[string]int64{}
+	reviewDto.Moderation = v1.NewModeration(v1.User{}.GetByID(userFromToken.ID))
+	filterResult, err := filterContent(enums.REVIEW, reviewDto.ID, reviewDto.ReviewerId,
+		v1.FilterField{Name: "review_title", Text: &reviewDto.ReviewTitle},
+		v1.FilterField{Name: "description", Text: &reviewDto.Description})
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: Review is rejected by content filter", err.Error())
+	}
+	if filterResult.Flagged {
+		reviewDto.Moderation = flaggedModeration(filterResult)
+	}
+	reviewDto = reviewDto.RenderMentions()
 	err = v1.Review{}.Store(reviewDto)
+	if err == v1.ErrReviewAlreadyExists {
+		existing = v1.Review{}.GetByReviewerAndMovie(userFromToken.ID, movie.ID)
+		return common.GenerateConflictResponse(context, v1.NewReviewConflictDto(existing.ID), "You have already reviewed this movie!")
+	}
 	if err != nil {
 		return common.GenerateErrorResponse(context, err, err.Error())
 	}
+	if diaryDto != nil {
+		diaryDto.ReviewId = reviewDto.ID
+		if entry, err := logDiaryEntry(userFromToken.ID, movie, *diaryDto); err != nil {
+			log.Println("[ERROR] Failed to log viewing of review:", err.Error())
+		} else {
+			publishDiaryEntry(entry)
+		}
+	}
+	if reviewDto.Moderation.State == enums.PENDING {
+		return common.GenerateSuccessResponse(context, "[SUCCESS]: Review is submitted for moderation", nil, "Operation Successful")
+	}
+	notifyMentions(enums.REVIEW, reviewDto.ID, reviewDto.ReviewerId, reviewDto.ReviewerEmail, nil, reviewDto.Mentions)
+	publishReview(reviewDto)
 	return common.GenerateSuccessResponse(context, "[SUCCESS]: Review is posted successfully", nil, "Operation Successful")
 }
 
+// GetDuplicates... Get Duplicates Api
+// @Summary Get duplicate reviews api
+// @Description Api for admins to get the migration report of users having more than one review of the same movie
+// @Tags Review
+// @Produce json
+// @Param Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Success 200 {object} common.ResponseDTO{data=[]v1.ReviewDuplicate{}}
+// @Forbidden 403 {object} common.ResponseDTO
+// @Failure 400 {object} common.ResponseDTO
+// @Router /api/v1/reviews/duplicates [GET]
+func (r reviewApi) GetDuplicates(context echo.Context) error {
+	userFromToken, err := GetUserTokenDtoFromBearerToken(context, v1.Jwt{})
+	if err != nil {
+		return common.GenerateErrorResponse(context, err.Error(), "Operation Failed!")
+	}
+	if userFromToken.Role != enums.SUPERADMIN && userFromToken.Role != enums.ADMIN {
+		return common.GenerateForbiddenResponse(context, "[ERROR]: Insufficient permission", "Operation Failed!")
+	}
+	return common.GenerateSuccessResponse(context, v1.Review{}.GetDuplicates(), nil, "Successful")
+}
+
+// Put... Put Api
+// @Summary Update review api
+// @Description Api for replacing title, description and spoiler flag of a review by its author
+// @Tags Review
+// @Produce json
+// @Param Authorization header string true "Insert your access token while updating review" default(Bearer <Add access token here>)
+// @Param id path string true "review id"
+// @Param data body v1.ReviewUpdateDto true "dto for updating review"
+// @Success 200 {object} common.ResponseDTO{data=v1.Review{}}
+// @Failure 400 {object} common.ResponseDTO
+// @Forbidden 403 {object} common.ResponseDTO
+// @Router /api/v1/reviews/{id} [PUT]
+func (r reviewApi) Put(context echo.Context) error {
+	return r.update(context, false)
+}
+
+// Patch... Patch Api
+// @Summary Patch review api
+// @Description Api for partially updating title, description or spoiler flag of a review by its author
+// @Tags Review
+// @Produce json
+// @Param Authorization header string true "Insert your access token while updating review" default(Bearer <Add access token here>)
+// @Param id path string true "review id"
+// @Param data body v1.ReviewUpdateDto true "dto for updating review, empty fields are left unchanged"
+// @Success 200 {object} common.ResponseDTO{data=v1.Review{}}
+// @Failure 400 {object} common.ResponseDTO
+// @Forbidden 403 {object} common.ResponseDTO
+// @Router /api/v1/reviews/{id} [PATCH]
+func (r reviewApi) Patch(context echo.Context) error {
+	return r.update(context, true)
+}
+
+func (r reviewApi) update(context echo.Context, partial bool) error {
+	userFromToken, err := GetUserTokenDtoFromBearerToken(context, v1.Jwt{})
+	if err != nil {
+		return common.GenerateErrorResponse(context, err.Error(), "Operation Failed!")
+	}
+	if userFromToken.ID == "" {
+		return common.GenerateUnauthorizedResponse(context, "[ERROR]: User not found!", "Please provide valid user information.")
+	}
+	id := context.Param("id")
+	if id == "" {
+		return common.GenerateErrorResponse(context, "[ERROR]: Review id is not provided", "Operation failed")
+	}
+	review := v1.Review{}.GetByID(id)
+	if review.ID == "" {
+		return common.GenerateErrorResponse(context, "[ERROR]: Review is not found!", "Please provide a valid review id!")
+	}
+	if review.ReviewerId != userFromToken.ID {
+		return common.GenerateForbiddenResponse(context, "[ERROR]: Insufficient permission", "Only the author can edit a review!")
+	}
+	updateDto := v1.ReviewUpdateDto{}
+	if err := context.Bind(&updateDto); err != nil {
+		log.Println("Input Error:", err.Error())
+		return common.GenerateErrorResponse(context, nil, "Failed to Bind Input!")
+	}
+	edited := review
+	if !partial || updateDto.ReviewTitle != "" {
+		edited.ReviewTitle = updateDto.ReviewTitle
+	}
+	if !partial || updateDto.Description != "" {
+		edited.Description = updateDto.Description
+	}
+	if updateDto.Spoiler != nil {
+		edited.Spoiler = *updateDto.Spoiler
+	} else if !partial {
+		edited.Spoiler = false
+	}
+	err = edited.Validate()
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: Invalid data provided", err.Error())
+	}
+	return r.saveEdit(context, userFromToken.ID, review, edited)
+}
+
+// saveEdit updates the review with the edited content and stores a revision of it.
+func (r reviewApi) saveEdit(context echo.Context, editorId string, review, edited v1.Review) error {
+	if edited.ReviewTitle == review.ReviewTitle && edited.Description == review.Description && edited.Spoiler == review.Spoiler {
+		return common.GenerateSuccessResponse(context, review, nil, "Nothing to update")
+	}
+	filterResult, err := filterContent(enums.REVIEW, review.ID, review.ReviewerId,
+		v1.FilterField{Name: "review_title", Text: &edited.ReviewTitle},
+		v1.FilterField{Name: "description", Text: &edited.Description})
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: Review is rejected by content filter", err.Error())
+	}
+	if filterResult.Flagged {
+		edited.Moderation = flaggedModeration(filterResult)
+	}
+	edited = edited.RenderMentions()
+	editedAt := time.Now().UTC()
+	edited.EditedAt = &editedAt
+	err = v1.Review{}.Update(edited)
+	if err != nil {
+		return common.GenerateErrorResponse(context, err, err.Error())
+	}
+	err = v1.ReviewRevision{}.Store(v1.NewReviewRevision(uuid.New().String(), editorId, review, edited))
+	if err != nil {
+		log.Println("[ERROR] Failed to store review revision:", err.Error())
+	}
+	if edited.Moderation.IsVisible() {
+		notifyMentions(enums.REVIEW, edited.ID, edited.ReviewerId, edited.ReviewerEmail, review.Mentions, edited.Mentions)
+	}
+	return common.GenerateSuccessResponse(context, edited, nil, "Operation Successful")
+}
+
+// getReviewDiaryDto returns the diary entry logged with a new review from the watched_on and
+// rewatch query params.
+func getReviewDiaryDto(context echo.Context, review v1.Review) (*v1.DiaryEntryDto, error) {
+	dto := v1.DiaryEntryDto{
+		MovieId:   review.Movie.ID,
+		WatchedOn: context.QueryParam("watched_on"),
+	}
+	if dto.WatchedOn == "" {
+		dto.WatchedOn = time.Now().UTC().Format(v1.DiaryDateLayout)
+	}
+	if rewatch := context.QueryParam("rewatch"); rewatch != "" {
+		value, err := strconv.ParseBool(rewatch)
+		if err != nil {
+			return nil, errors.New("rewatch must be true or false")
+		}
+		dto.Rewatch = &value
+	}
+	if err := dto.Validate(time.Now()); err != nil {
+		return nil, err
+	}
+	return &dto, nil
+}
+
+// GetRevisions... Get Revisions Api
+// @Summary Get review revisions api
+// @Description Api for getting edit history of a review, visible to its author and admins
+// @Tags Review
+// @Produce json
+// @Param Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param id path string true "review id"
+// @Param page query string false "page"
+// @Param limit query string false "limit"
+// @Success 200 {object} common.ResponseDTO{data=[]v1.ReviewRevision{}}
+// @Forbidden 403 {object} common.ResponseDTO
+// @Failure 400 {object} common.ResponseDTO
+// @Router /api/v1/reviews/{id}/revisions [GET]
+func (r reviewApi) GetRevisions(context echo.Context) error {
+	userFromToken, err := GetUserTokenDtoFromBearerToken(context, v1.Jwt{})
+	if err != nil {
+		return common.GenerateErrorResponse(context, err.Error(), "Operation Failed!")
+	}
+	id := context.Param("id")
+	if id == "" {
+		return common.GenerateErrorResponse(context, "[ERROR]: Review id is not provided", "Operation failed")
+	}
+	review := v1.Review{}.GetByID(id)
+	if review.ID == "" {
+		return common.GenerateErrorResponse(context, "[ERROR]: Review is not found!", "Please provide a valid review id!")
+	}
+	if review.ReviewerId != userFromToken.ID && userFromToken.Role != enums.ADMIN && userFromToken.Role != enums.SUPERADMIN {
+		return common.GenerateForbiddenResponse(context, "[ERROR]: Insufficient permission", "Operation Failed!")
+	}
+	pagination := getPagination(context)
+	data, total := v1.ReviewRevision{}.GetByReviewId(id, pagination)
+	metadata := getPaginationMetadataWithLinks(context, pagination, total, int64(len(data)), nil)
+	return common.GenerateSuccessResponse(context, data,
+		&metadata, "Successful")
+}
+
 // Delete... Delete Api
 // @Summary Delete review api
 // @Description Api for deleting review
@@ -201,5 +446,163 @@ func (r reviewApi) Delete(context echo.Context) error {
 	if err != nil {
 		return common.GenerateErrorResponse(context, err, err.Error())
 	}
+	if err := (v1.DiaryEntry{}).UnlinkReview(id); err != nil {
+		log.Println("[ERROR] Failed to unlink review from diary:", err.Error())
+	}
+	retractActivity(enums.REVIEW_POSTED, id)
 	return common.GenerateSuccessResponse(context, "[SUCCESS]: Review is deleted successfully", nil, "Operation Successful")
 }
+
+// Vote... Vote Api
+// @Summary Vote review api
+// @Description Api for voting a review helpful or unhelpful, voting again replaces the previous vote
+// @Tags Review
+// @Produce json
+// @Param Authorization header string true "Insert your access token while voting review" default(Bearer <Add access token here>)
+// @Param id path string true "review id"
+// @Param data body v1.ReviewVoteDto true "dto for voting review"
+// @Success 200 {object} common.ResponseDTO{data=v1.Review{}}
+// @Failure 400 {object} common.ResponseDTO
+// @Forbidden 403 {object} common.ResponseDTO
+// @Router /api/v1/reviews/{id}/votes [POST]
+func (r reviewApi) Vote(context echo.Context) error {
+	userFromToken, err := GetUserTokenDtoFromBearerToken(context, v1.Jwt{})
+	if err != nil {
+		return common.GenerateErrorResponse(context, err.Error(), "Operation Failed!")
+	}
+	if userFromToken.ID == "" {
+		return common.GenerateUnauthorizedResponse(context, "[ERROR]: User not found!", "Please provide valid user information.")
+	}
+	id := context.Param("id")
+	if id == "" {
+		return common.GenerateErrorResponse(context, "[ERROR]: Review id is not provided", "Operation failed")
+	}
+	review := v1.Review{}.GetByID(id)
+	if review.ID == "" {
+		return common.GenerateErrorResponse(context, "[ERROR]: Review is not found!", "Please provide a valid review id!")
+	}
+	if !review.Moderation.IsVisible() {
+		return common.GenerateErrorResponse(context, "[ERROR]: Review is not found!", "Please provide a valid review id!")
+	}
+	if review.ReviewerId == userFromToken.ID {
+		return common.GenerateForbiddenResponse(context, "[ERROR]: Insufficient permission", "You can not vote your own review!")
+	}
+	if (v1.UserRelation{}).IsBlockedByAny(userFromToken.ID, review.ReviewerId) {
+		return common.GenerateForbiddenResponse(context, "[ERROR]: Insufficient permission", "You are blocked by the author!")
+	}
+	voteDto := v1.ReviewVoteDto{}
+	if err := context.Bind(&voteDto); err != nil {
+		log.Println("Input Error:", err.Error())
+		return common.GenerateErrorResponse(context, nil, "Failed to Bind Input!")
+	}
+	err = voteDto.Validate()
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: Invalid data provided", err.Error())
+	}
+	err = v1.ReviewVote{}.Upsert(v1.ReviewVote{
+		ReviewId:  review.ID,
+		VoterId:   userFromToken.ID,
+		Helpful:   *voteDto.Helpful,
+		CreatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		return common.GenerateErrorResponse(context, err, err.Error())
+	}
+	return r.refreshHelpfulness(context, review)
+}
+
+// RetractVote... Retract Vote Api
+// @Summary Retract review vote api
+// @Description Api for retracting own helpfulness vote of a review
+// @Tags Review
+// @Produce json
+// @Param Authorization header string true "Insert your access token while retracting vote" default(Bearer <Add access token here>)
+// @Param id path string true "review id"
+// @Success 200 {object} common.ResponseDTO{data=v1.Review{}}
+// @Failure 400 {object} common.ResponseDTO
+// @Forbidden 403 {object} common.ResponseDTO
+// @Router /api/v1/reviews/{id}/votes [DELETE]
+func (r reviewApi) RetractVote(context echo.Context) error {
+	userFromToken, err := GetUserTokenDtoFromBearerToken(context, v1.Jwt{})
+	if err != nil {
+		return common.GenerateErrorResponse(context, err.Error(), "Operation Failed!")
+	}
+	if userFromToken.ID == "" {
+		return common.GenerateUnauthorizedResponse(context, "[ERROR]: User not found!", "Please provide valid user information.")
+	}
+	id := context.Param("id")
+	if id == "" {
+		return common.GenerateErrorResponse(context, "[ERROR]: Review id is not provided", "Operation failed")
+	}
+	review := v1.Review{}.GetByID(id)
+	if review.ID == "" {
+		return common.GenerateErrorResponse(context, "[ERROR]: Review is not found!", "Please provide a valid review id!")
+	}
+	err = v1.ReviewVote{}.Delete(review.ID, userFromToken.ID)
+	if err != nil {
+		return common.GenerateErrorResponse(context, err, err.Error())
+	}
+	return r.refreshHelpfulness(context, review)
+}
+
+// refreshHelpfulness recounts the votes of a review and responds with the updated review.
+func (r reviewApi) refreshHelpfulness(context echo.Context, review v1.Review) error {
+	helpful, unhelpful := v1.ReviewVote{}.CountByReviewId(review.ID)
+	err := v1.Review{}.UpdateHelpfulness(review.ID, helpful, unhelpful)
+	if err != nil {
+		return common.GenerateErrorResponse(context, err, err.Error())
+	}
+	review.Helpful = helpful
+	review.Unhelpful = unhelpful
+	review.HelpfulScore = v1.WilsonScore(helpful, unhelpful)
+	return common.GenerateSuccessResponse(context, review, nil, "Operation Successful")
+}
+
+// Report... Report Api
+// @Summary Report review api
+// @Description Api for reporting an abusive review, a user can report a review once
+// @Tags Review
+// @Produce json
+// @Param Authorization header string true "Insert your access token while reporting review" default(Bearer <Add access token here>)
+// @Param id path string true "review id"
+// @Param data body v1.ReportDto true "dto for reporting review"
+// @Success 200 {object} common.ResponseDTO
+// @Failure 400 {object} common.ResponseDTO
+// @Forbidden 403 {object} common.ResponseDTO
+// @Failure 409 {object} common.ResponseDTO
+// @Router /api/v1/reviews/{id}/reports [POST]
+func (r reviewApi) Report(context echo.Context) error {
+	return postReport(context, enums.REVIEW)
+}
+
+// React... React Api
+// @Summary React to review api
+// @Description Api for toggling a reaction to a review, reacting again with the same emoji removes the reaction
+// @Tags Review
+// @Produce json
+// @Param Authorization header string true "Insert your access token while reacting" default(Bearer <Add access token here>)
+// @Param id path string true "review id"
+// @Param data body v1.ReactionDto true "dto for reacting"
+// @Success 200 {object} common.ResponseDTO{data=v1.ReactionResult{}}
+// @Failure 400 {object} common.ResponseDTO
+// @Forbidden 403 {object} common.ResponseDTO
+// @Router /api/v1/reviews/{id}/reactions [POST]
+func (r reviewApi) React(context echo.Context) error {
+	return postReaction(context, enums.REVIEW)
+}
+
+// GetReactions... Get Reactions Api
+// @Summary Get review reactions api
+// @Description Api for listing who reacted to a review
+// @Tags Review
+// @Produce json
+// @Param id path string true "review id"
+// @Param emoji query string false "emoji"
+// @Param page query string false "page"
+// @Param limit query string false "limit"
+// @Success 200 {object} common.ResponseDTO{data=[]v1.ReactionView{}}
+// @Failure 400 {object} common.ResponseDTO
+// @Router /api/v1/reviews/{id}/reactions [GET]
+func (r reviewApi) GetReactions(context echo.Context) error {
+	return getReactions(context, enums.REVIEW)
+}
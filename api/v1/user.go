@@ -1,21 +1,64 @@
 package v1
 
 import (
+	"encoding/csv"
 	"errors"
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	"github.com/niloydeb1/Golang-Movie_API/api/common"
+	"github.com/niloydeb1/Golang-Movie_API/config"
 	"github.com/niloydeb1/Golang-Movie_API/enums"
 	v1 "github.com/niloydeb1/Golang-Movie_API/src/v1"
+	"go.mongodb.org/mongo-driver/bson"
 	"golang.org/x/crypto/bcrypt"
+	"io"
 	"log"
+	"net/http"
+	"net/url"
 	"time"
 )
 
 func UserRouter(g *echo.Group) {
 	g.POST("", userApi{}.Registration)
+	g.GET("/verify", userApi{}.VerifyEmail)
+	g.POST("/verification-mails", userApi{}.ResendVerificationMail)
+	g.POST("/password-resets", userApi{}.ForgotPassword)
+	g.PUT("/password-resets", userApi{}.ConfirmPasswordReset)
 	g.GET("", userApi{}.Get)
+	g.PATCH("/me", userApi{}.UpdateProfile)
+	g.PUT("/me/email", userApi{}.ChangeEmail)
+	g.GET("/me/email/verify", userApi{}.VerifyEmailChange)
+	g.PUT("/me/avatar", userApi{}.UploadAvatar)
+	g.DELETE("/me/avatar", userApi{}.DeleteAvatar)
+	g.GET("/me/exports", exportApi{}.Get)
+	g.POST("/me/exports", exportApi{}.Post)
+	g.GET("/me/blocks", relationApi{}.GetBlocks)
+	g.GET("/me/mutes", relationApi{}.GetMutes)
+	g.GET("/me/watchlist", watchlistApi{}.Get)
+	g.POST("/me/watchlist", watchlistApi{}.Post)
+	g.PATCH("/me/watchlist/:movie_id", watchlistApi{}.Patch)
+	g.DELETE("/me/watchlist/:movie_id", watchlistApi{}.Delete)
+	g.GET("/me/diary", diaryApi{}.Get)
+	g.POST("/me/diary", diaryApi{}.Post)
+	g.GET("/me/diary/calendar", diaryApi{}.GetCalendar)
+	g.GET("/me/diary/:id", diaryApi{}.GetByID)
+	g.PATCH("/me/diary/:id", diaryApi{}.Patch)
+	g.DELETE("/me/diary/:id", diaryApi{}.Delete)
 	g.GET("/:id", userApi{}.GetByID)
+	g.GET("/:id/avatar", userApi{}.GetAvatar)
+	g.GET("/:id/profile", userApi{}.GetProfile)
+	g.GET("/:id/reviews", userApi{}.GetReviews)
+	g.GET("/:id/comments", userApi{}.GetComments)
+	g.POST("/:id/follow", followApi{}.Follow)
+	g.DELETE("/:id/follow", followApi{}.Unfollow)
+	g.GET("/:id/followers", followApi{}.GetFollowers)
+	g.GET("/:id/following", followApi{}.GetFollowing)
+	g.POST("/:id/block", relationApi{}.Block)
+	g.DELETE("/:id/block", relationApi{}.Unblock)
+	g.POST("/:id/mute", relationApi{}.Mute)
+	g.DELETE("/:id/mute", relationApi{}.Unmute)
+	g.GET("/:id/exports", exportApi{}.GetForUser)
+	g.POST("/:id/exports", exportApi{}.PostForUser)
 	g.DELETE("/:id", userApi{}.Delete)
 	g.PUT("", userApi{}.Update)
 }
@@ -25,12 +68,21 @@ type userApi struct {
 
 // Get... Get Api
 // @Summary Get api
-// @Description Api for getiing all user by admin
+// @Description Api for searching users by admin. Users of every status but deleted are listed unless a status is given
 // @Tags User
 // @Produce json
+// @Produce text/csv
 // @Param Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
-// @Param status path string true "status type [active/inactive]"
-// @Success 200 {object} common.ResponseDTO{data=[]v1.User{}}
+// @Param q query string false "prefix of first name, last name or email"
+// @Param role query string false "role [SUPERADMIN/ADMIN/USER]"
+// @Param status query string false "status [active/inactive/deleted/pending_verification]"
+// @Param created_from query string false "created on or after, RFC3339 or YYYY-MM-DD"
+// @Param created_to query string false "created before, RFC3339 or YYYY-MM-DD"
+// @Param sort query string false "sort order [newest/oldest/name/email]"
+// @Param format query string false "set csv to export every matching user as csv"
+// @Param page query string false "page"
+// @Param limit query string false "limit"
+// @Success 200 {object} common.ResponseDTO{data=[]v1.UserAdminView{}}
 // @Forbidden 403 {object} common.ResponseDTO
 // @Failure 400 {object} common.ResponseDTO
 // @Router /api/v1/users [GET]
@@ -42,23 +94,73 @@ func (u userApi) Get(context echo.Context) error {
 	if userFromToken.Role != enums.SUPERADMIN && userFromToken.Role != enums.ADMIN {
 		return common.GenerateForbiddenResponse(context, "[ERROR]: Insufficient permission", "Operation Failed!")
 	}
-	status := context.QueryParam("status")
-	if status == string(enums.ACTIVE) {
-		return common.GenerateSuccessResponse(context, v1.User{}.GetUsers(enums.STATUS(status)), nil, "Success!")
-	} else if status == string(enums.INACTIVE) {
-		return common.GenerateSuccessResponse(context, v1.User{}.GetUsers(enums.STATUS(status)), nil, "Success!")
+	filter, err := getUserFilter(context)
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: Invalid filter is provided", err.Error())
+	}
+	sort, err := getUserSort(context)
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: Invalid sort is provided", err.Error())
+	}
+	switch context.QueryParam("format") {
+	case "":
+	case "csv":
+		return exportUsersCsv(context, filter.Query(), sort)
+	default:
+		return common.GenerateErrorResponse(context, "[ERROR]: Invalid format is provided", "format must be csv if provided")
+	}
+	pagination := getPagination(context)
+	data, total := v1.User{}.Search(filter.Query(), pagination, sort)
+	query := url.Values{}
+	for _, key := range []string{"q", "role", "status", "created_from", "created_to", "sort"} {
+		if value := context.QueryParam(key); value != "" {
+			query.Set(key, value)
+		}
 	}
-	return common.GenerateForbiddenResponse(context, "[ERROR]: No valid status found!", "Please provide a valid status.")
+	metadata := getPaginationMetadataWithLinks(context, pagination, total, int64(len(data)), query)
+	return common.GenerateSuccessResponse(context, v1.NewUserAdminViews(data), &metadata, "Success!")
+}
+
+// exportUsersCsv writes every user matching the query to the response as csv. Rows are read before
+// the response is started, so a failed query is reported as an error instead of a truncated file.
+func exportUsersCsv(context echo.Context, query bson.M, sort enums.USER_SORT) error {
+	rows := [][]string{{"id", "first_name", "last_name", "email", "phone", "role", "status", "created_date"}}
+	err := v1.User{}.Each(query, sort, func(user v1.User) error {
+		view := v1.NewUserAdminView(user)
+		rows = append(rows, []string{
+			view.ID,
+			v1.CsvSafe(view.FirstName),
+			v1.CsvSafe(view.LastName),
+			v1.CsvSafe(view.Email),
+			v1.CsvSafePhone(view.Phone),
+			string(view.Role),
+			string(view.Status),
+			view.CreatedDate.Format(time.RFC3339),
+		})
+		return nil
+	})
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: Failed to export users!", err.Error())
+	}
+	response := context.Response()
+	response.Header().Set(echo.HeaderContentType, "text/csv; charset=utf-8")
+	response.Header().Set(echo.HeaderContentDisposition, "attachment; filename=\"users.csv\"")
+	response.WriteHeader(http.StatusOK)
+	writer := csv.NewWriter(response)
+	if err := writer.WriteAll(rows); err != nil {
+		log.Println("[ERROR] Failed to export users:", err.Error())
+	}
+	return nil
 }
 
 // GetByID... GetByID Api
 // @Summary Registration api
-// @Description Api for getiing user by id
+// @Description Api for getiing user by id, users get their own self view and admins get the admin view
 // @Tags User
 // @Produce json
 // @Param Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
 // @Param id path string true "id user id"
-// @Success 200 {object} common.ResponseDTO{data=v1.User{}}
+// @Success 200 {object} common.ResponseDTO{data=v1.UserSelfView{}}
 // @Failure 400 {object} common.ResponseDTO
 // @Forbidden 403 {object} common.ResponseDTO
 // @Router /api/v1/users/{id} [GET]
@@ -77,7 +179,10 @@ func (u userApi) GetByID(context echo.Context) error {
 	if data.ID == "" {
 		return common.GenerateErrorResponse(context, "[ERROR]: User Not Found!", "Please give a valid user id!")
 	}
-	return common.GenerateSuccessResponse(context, data, nil, "Success!")
+	if userFromToken.ID == id {
+		return common.GenerateSuccessResponse(context, v1.NewUserSelfView(data), nil, "Success!")
+	}
+	return common.GenerateSuccessResponse(context, v1.NewUserAdminView(data), nil, "Success!")
 }
 
 // Registration... Registration Api
@@ -138,7 +243,7 @@ func (u userApi) registerAdmin(context echo.Context) error {
 	if err != nil {
 		return common.GenerateErrorResponse(context, "[ERROR]: Failed to register user!", err.Error())
 	}
-	return common.GenerateSuccessResponse(context, formData, nil, "Successfully Created User!")
+	return common.GenerateSuccessResponse(context, v1.NewUserAdminView(user), nil, "Successfully Created User!")
 }
 
 func (u userApi) registerUser(context echo.Context) error {
@@ -155,7 +260,7 @@ func (u userApi) registerUser(context echo.Context) error {
 	formData.ID = uuid.New().String()
 	formData.CreatedDate = time.Now().UTC()
 	formData.UpdatedDate = time.Now().UTC()
-	formData.Status = enums.ACTIVE
+	formData.Status = enums.PENDING_VERIFICATION
 	err := formData.Validate()
 	if err != nil {
 		return common.GenerateErrorResponse(context, "[ERROR]: Failed to register user!", err.Error())
@@ -170,7 +275,463 @@ func (u userApi) registerUser(context echo.Context) error {
 	if err != nil {
 		return common.GenerateErrorResponse(context, "[ERROR]: Failed to register user!", err.Error())
 	}
-	return common.GenerateSuccessResponse(context, formData, nil, "Successfully Created User!")
+	err = v1.SendVerificationMail(v1.NewMailer(), user)
+	if err != nil {
+		log.Println("[ERROR] Failed to send verification mail:", err.Error())
+	}
+	return common.GenerateSuccessResponse(context, v1.NewUserSelfView(user), nil, "Successfully Created User!")
+}
+
+// VerifyEmail... Verify Email Api
+// @Summary Verify email api
+// @Description Api for verifying email of a registered user with the token sent by mail
+// @Tags User
+// @Produce json
+// @Param token query string true "email verification token"
+// @Success 200 {object} common.ResponseDTO
+// @Failure 400 {object} common.ResponseDTO
+// @Router /api/v1/users/verify [GET]
+func (u userApi) VerifyEmail(context echo.Context) error {
+	token := context.QueryParam("token")
+	if token == "" {
+		return common.GenerateErrorResponse(context, "[ERROR]: Token is not provided!", "Please provide a valid token!")
+	}
+	_, err := v1.VerifyEmail(token)
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: Failed to verify email!", err.Error())
+	}
+	return common.GenerateSuccessResponse(context, "[SUCCESS]: Email is verified, please login", nil, "Operation Successful!")
+}
+
+// ResendVerificationMail... Resend Verification Mail Api
+// @Summary Resend verification mail api
+// @Description Api for resending the email verification mail of a registered user, rate limited per user
+// @Tags User
+// @Produce json
+// @Param data body v1.EmailVerificationDto true "dto for resending verification mail"
+// @Success 200 {object} common.ResponseDTO
+// @Failure 400 {object} common.ResponseDTO
+// @Failure 429 {object} common.ResponseDTO
+// @Router /api/v1/users/verification-mails [POST]
+func (u userApi) ResendVerificationMail(context echo.Context) error {
+	formData := v1.EmailVerificationDto{}
+	if err := context.Bind(&formData); err != nil {
+		log.Println("Input Error:", err.Error())
+		return common.GenerateErrorResponse(context, nil, "Failed to Bind Input!")
+	}
+	if formData.Email == "" {
+		return common.GenerateErrorResponse(context, "[ERROR]: Email is not provided!", "Please provide a valid email!")
+	}
+	// the same response is given for unknown and verified emails, so that registered emails are not revealed
+	response := "[SUCCESS]: A verification mail is sent if the email is waiting for verification"
+	user := v1.User{}.GetByEmail(formData.Email)
+	if user.ID == "" || user.Status != enums.PENDING_VERIFICATION {
+		return common.GenerateSuccessResponse(context, response, nil, "Operation Successful!")
+	}
+	err := v1.SendVerificationMail(v1.NewMailer(), user)
+	if err == v1.ErrVerificationMailRateLimited {
+		return common.GenerateTooManyRequestsResponse(context, "[ERROR]: "+err.Error(), "Operation Failed!")
+	}
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: Failed to send verification mail!", err.Error())
+	}
+	return common.GenerateSuccessResponse(context, response, nil, "Operation Successful!")
+}
+
+// ForgotPassword... Forgot Password Api
+// @Summary Forgot password api
+// @Description Api for requesting a password reset token by mail
+// @Tags User
+// @Produce json
+// @Param data body v1.ForgotPasswordDto true "dto for requesting password reset"
+// @Success 200 {object} common.ResponseDTO
+// @Failure 400 {object} common.ResponseDTO
+// @Failure 429 {object} common.ResponseDTO
+// @Router /api/v1/users/password-resets [POST]
+func (u userApi) ForgotPassword(context echo.Context) error {
+	formData := v1.ForgotPasswordDto{}
+	if err := context.Bind(&formData); err != nil {
+		log.Println("Input Error:", err.Error())
+		return common.GenerateErrorResponse(context, nil, "Failed to Bind Input!")
+	}
+	if formData.Email == "" {
+		return common.GenerateErrorResponse(context, "[ERROR]: Email is not provided!", "Please provide a valid email!")
+	}
+	// the same response is given for unknown emails, so that registered emails are not revealed
+	response := "[SUCCESS]: A password reset token is sent if the email is registered"
+	user := v1.User{}.GetByEmail(formData.Email)
+	if user.ID == "" || user.Status != enums.ACTIVE {
+		return common.GenerateSuccessResponse(context, response, nil, "Operation Successful!")
+	}
+	err := v1.SendPasswordResetMail(v1.NewMailer(), user)
+	if err == v1.ErrPasswordResetMailRateLimited {
+		return common.GenerateTooManyRequestsResponse(context, "[ERROR]: "+err.Error(), "Operation Failed!")
+	}
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: Failed to send password reset mail!", err.Error())
+	}
+	return common.GenerateSuccessResponse(context, response, nil, "Operation Successful!")
+}
+
+// ConfirmPasswordReset... Confirm Password Reset Api
+// @Summary Confirm password reset api
+// @Description Api for setting a new password with a password reset token. Every session of the user is logged out
+// @Tags User
+// @Produce json
+// @Param data body v1.PasswordResetConfirmDto true "dto for setting new password"
+// @Success 200 {object} common.ResponseDTO
+// @Failure 400 {object} common.ResponseDTO
+// @Forbidden 403 {object} common.ResponseDTO
+// @Router /api/v1/users/password-resets [PUT]
+func (u userApi) ConfirmPasswordReset(context echo.Context) error {
+	formData := v1.PasswordResetConfirmDto{}
+	if err := context.Bind(&formData); err != nil {
+		log.Println("Input Error:", err.Error())
+		return common.GenerateErrorResponse(context, nil, "Failed to Bind Input!")
+	}
+	err := formData.Validate()
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: Failed to reset password!", err.Error())
+	}
+	reset, err := v1.PasswordReset{}.Consume(formData.Token)
+	if err != nil {
+		return common.GenerateForbiddenResponse(context, "[ERROR]: Failed to reset password!", err.Error())
+	}
+	user := v1.User{}.GetByID(reset.UserId)
+	if user.ID == "" || user.Status != enums.ACTIVE {
+		return common.GenerateForbiddenResponse(context, "[ERROR]: No User found!", "Please request a new password reset!")
+	}
+	user.Password = formData.NewPassword
+	err = v1.User{}.UpdatePassword(user)
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: Failed to reset password!", err.Error())
+	}
+	err = v1.TokenService{}.DeleteByUID(user.ID)
+	if err != nil {
+		log.Println("[ERROR] Failed to revoke tokens:", err.Error())
+	}
+	return common.GenerateSuccessResponse(context, "[SUCCESS]: Password is reset, please login", nil, "Operation Successful!")
+}
+
+// UpdateProfile... Update Profile Api
+// @Summary Update own profile api
+// @Description Api for updating own first name, last name or phone, phone must be in E.164 format
+// @Tags User
+// @Produce json
+// @Param Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param data body v1.UserProfileDto true "dto for updating profile, omitted fields are left unchanged"
+// @Success 200 {object} common.ResponseDTO{data=v1.UserSelfView{}}
+// @Failure 400 {object} common.ResponseDTO
+// @Forbidden 403 {object} common.ResponseDTO
+// @Router /api/v1/users/me [PATCH]
+func (u userApi) UpdateProfile(context echo.Context) error {
+	userFromToken, err := GetUserTokenDtoFromBearerToken(context, v1.Jwt{})
+	if err != nil {
+		return common.GenerateErrorResponse(context, err.Error(), "Operation Failed!")
+	}
+	profile := v1.UserProfileDto{}
+	if err := context.Bind(&profile); err != nil {
+		log.Println("Input Error:", err.Error())
+		return common.GenerateErrorResponse(context, nil, "Failed to Bind Input!")
+	}
+	err = profile.Validate()
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: Invalid data provided", err.Error())
+	}
+	err = v1.User{}.UpdateProfile(userFromToken.ID, profile)
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: Failed to update profile!", err.Error())
+	}
+	return common.GenerateSuccessResponse(context, v1.NewUserSelfView(v1.User{}.GetByID(userFromToken.ID)), nil, "Operation Successful!")
+}
+
+// ChangeEmail... Change Email Api
+// @Summary Change own email api
+// @Description Api for changing own email. The new email is used after it is verified with the link sent to it
+// @Tags User
+// @Produce json
+// @Param Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param data body v1.EmailChangeDto true "dto for changing email"
+// @Success 200 {object} common.ResponseDTO
+// @Failure 400 {object} common.ResponseDTO
+// @Forbidden 403 {object} common.ResponseDTO
+// @Failure 429 {object} common.ResponseDTO
+// @Router /api/v1/users/me/email [PUT]
+func (u userApi) ChangeEmail(context echo.Context) error {
+	userFromToken, err := GetUserTokenDtoFromBearerToken(context, v1.Jwt{})
+	if err != nil {
+		return common.GenerateErrorResponse(context, err.Error(), "Operation Failed!")
+	}
+	formData := v1.EmailChangeDto{}
+	if err := context.Bind(&formData); err != nil {
+		log.Println("Input Error:", err.Error())
+		return common.GenerateErrorResponse(context, nil, "Failed to Bind Input!")
+	}
+	err = formData.Validate()
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: Invalid data provided", err.Error())
+	}
+	user := v1.User{}.GetByID(userFromToken.ID)
+	if user.ID == "" || user.Status != enums.ACTIVE {
+		return common.GenerateForbiddenResponse(context, "[ERROR]: No User found!", "Please login with actual user email!")
+	}
+	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(formData.CurrentPassword))
+	if err != nil {
+		return common.GenerateForbiddenResponse(context, "[ERROR]: Password not matched!", "Please provide due credential!")
+	}
+	if formData.Email == user.Email {
+		return common.GenerateSuccessResponse(context, nil, nil, "Nothing to update")
+	}
+	if (v1.User{}).GetByEmail(formData.Email).ID != "" {
+		return common.GenerateErrorResponse(context, "[ERROR]: Failed to change email!", "Email is already registered.")
+	}
+	if v1.VerificationMailRateLimited(user.ID, time.Now().UTC()) {
+		return common.GenerateTooManyRequestsResponse(context, "[ERROR]: "+v1.ErrVerificationMailRateLimited.Error(), "Operation Failed!")
+	}
+	err = v1.User{}.SetPendingEmail(user.ID, formData.Email)
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: Failed to change email!", err.Error())
+	}
+	err = v1.SendEmailChangeMail(v1.NewMailer(), user, formData.Email)
+	if err == v1.ErrVerificationMailRateLimited {
+		return common.GenerateTooManyRequestsResponse(context, "[ERROR]: "+err.Error(), "Operation Failed!")
+	}
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: Failed to send verification mail!", err.Error())
+	}
+	return common.GenerateSuccessResponse(context, "[SUCCESS]: Please verify your new email with the link sent to it", nil, "Operation Successful!")
+}
+
+// VerifyEmailChange... Verify Email Change Api
+// @Summary Verify email change api
+// @Description Api for confirming a new email with the token sent to it. Every session of the user is logged out
+// @Tags User
+// @Produce json
+// @Param token query string true "email change token"
+// @Success 200 {object} common.ResponseDTO
+// @Failure 400 {object} common.ResponseDTO
+// @Router /api/v1/users/me/email/verify [GET]
+func (u userApi) VerifyEmailChange(context echo.Context) error {
+	token := context.QueryParam("token")
+	if token == "" {
+		return common.GenerateErrorResponse(context, "[ERROR]: Token is not provided!", "Please provide a valid token!")
+	}
+	_, data, _ := v1.Jwt{}.ParseScopedToken(token, enums.EMAIL_CHANGE)
+	user, err := v1.VerifyEmailChange(token)
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: Failed to verify email!", err.Error())
+	}
+	// tokens carry the email of the user, so sessions have to login again with the new email
+	err = v1.TokenService{}.DeleteByUID(user.ID)
+	if err != nil {
+		log.Println("[ERROR] Failed to revoke tokens:", err.Error())
+	}
+	// reviews and comments keep a copy of the email of their author and of mentioned users
+	if err := (v1.Review{}).UpdateReviewerEmail(user.ID, user.Email); err != nil {
+		log.Println("[ERROR] Failed to update email of reviews:", err.Error())
+	}
+	if err := (v1.Comment{}).UpdateCommenterEmail(user.ID, user.Email); err != nil {
+		log.Println("[ERROR] Failed to update email of comments:", err.Error())
+	}
+	err = v1.NewMailer().Send(v1.Mail{
+		To:      data["current_email"],
+		Subject: "Your email is changed",
+		Body:    "Hi " + user.FirstName + ",\n\nThe email of your account is changed to " + user.Email + ".\n",
+	})
+	if err != nil {
+		log.Println("[ERROR] Failed to send email change notice:", err.Error())
+	}
+	return common.GenerateSuccessResponse(context, "[SUCCESS]: Email is changed, please login with your new email", nil, "Operation Successful!")
+}
+
+// UploadAvatar... Upload Avatar Api
+// @Summary Upload own avatar api
+// @Description Api for uploading own avatar. A jpeg, png or gif image is cropped to a square and resized
+// @Tags User
+// @Accept multipart/form-data
+// @Produce json
+// @Param Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param avatar formData file true "avatar image"
+// @Success 200 {object} common.ResponseDTO
+// @Failure 400 {object} common.ResponseDTO
+// @Forbidden 403 {object} common.ResponseDTO
+// @Router /api/v1/users/me/avatar [PUT]
+func (u userApi) UploadAvatar(context echo.Context) error {
+	userFromToken, err := GetUserTokenDtoFromBearerToken(context, v1.Jwt{})
+	if err != nil {
+		return common.GenerateErrorResponse(context, err.Error(), "Operation Failed!")
+	}
+	fileHeader, err := context.FormFile("avatar")
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: Avatar is not provided!", err.Error())
+	}
+	if fileHeader.Size > config.AvatarMaxBytes {
+		return common.GenerateErrorResponse(context, "[ERROR]: Failed to upload avatar!", v1.ErrAvatarTooLarge.Error())
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: Failed to upload avatar!", err.Error())
+	}
+	defer file.Close()
+	data, err := io.ReadAll(io.LimitReader(file, config.AvatarMaxBytes+1))
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: Failed to upload avatar!", err.Error())
+	}
+	avatar, err := v1.ProcessAvatar(data)
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: Failed to upload avatar!", err.Error())
+	}
+	err = v1.NewBlobStore().Put(v1.AvatarBlobKey(userFromToken.ID), avatar)
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: Failed to upload avatar!", err.Error())
+	}
+	err = v1.User{}.UpdateAvatar(userFromToken.ID, v1.AvatarUrl(userFromToken.ID))
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: Failed to upload avatar!", err.Error())
+	}
+	return common.GenerateSuccessResponse(context, v1.AvatarUrl(userFromToken.ID), nil, "Operation Successful!")
+}
+
+// DeleteAvatar... Delete Avatar Api
+// @Summary Delete own avatar api
+// @Description Api for removing own avatar
+// @Tags User
+// @Produce json
+// @Param Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Success 200 {object} common.ResponseDTO
+// @Failure 400 {object} common.ResponseDTO
+// @Forbidden 403 {object} common.ResponseDTO
+// @Router /api/v1/users/me/avatar [DELETE]
+func (u userApi) DeleteAvatar(context echo.Context) error {
+	userFromToken, err := GetUserTokenDtoFromBearerToken(context, v1.Jwt{})
+	if err != nil {
+		return common.GenerateErrorResponse(context, err.Error(), "Operation Failed!")
+	}
+	err = v1.NewBlobStore().Delete(v1.AvatarBlobKey(userFromToken.ID))
+	if err != nil && err != v1.ErrBlobNotFound {
+		return common.GenerateErrorResponse(context, "[ERROR]: Failed to delete avatar!", err.Error())
+	}
+	err = v1.User{}.UpdateAvatar(userFromToken.ID, "")
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: Failed to delete avatar!", err.Error())
+	}
+	return common.GenerateSuccessResponse(context, "[SUCCESS]: Avatar is deleted", nil, "Operation Successful!")
+}
+
+// GetAvatar... Get Avatar Api
+// @Summary Get avatar api
+// @Description Api for getting avatar image of a user
+// @Tags User
+// @Produce png
+// @Param id path string true "user id"
+// @Success 200 {file} binary
+// @Failure 400 {object} common.ResponseDTO
+// @Router /api/v1/users/{id}/avatar [GET]
+func (u userApi) GetAvatar(context echo.Context) error {
+	user := v1.User{}.GetByID(context.Param("id"))
+	if user.ID == "" || user.Status == enums.DELETED || user.Avatar == "" {
+		return common.GenerateErrorResponse(context, "[ERROR]: Avatar is not found!", "Please provide a valid user id!")
+	}
+	data, err := v1.NewBlobStore().Get(v1.AvatarBlobKey(user.ID))
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: Avatar is not found!", err.Error())
+	}
+	return context.Blob(http.StatusOK, "image/png", data)
+}
+
+// GetProfile... Get Profile Api
+// @Summary Get public profile api
+// @Description Api for getting public profile of a user with stats of their visible reviews and comments
+// @Tags User
+// @Produce json
+// @Param id path string true "user id"
+// @Success 200 {object} common.ResponseDTO{data=v1.UserProfile{}}
+// @Failure 400 {object} common.ResponseDTO
+// @Router /api/v1/users/{id}/profile [GET]
+func (u userApi) GetProfile(context echo.Context) error {
+	user, err := getPublicUser(context)
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: User Not Found!", err.Error())
+	}
+	return common.GenerateSuccessResponse(context, v1.NewUserProfile(user), nil, "Success!")
+}
+
+// GetReviews... Get Reviews Api
+// @Summary Get reviews of user api
+// @Description Api for getting visible reviews written by a user
+// @Tags User
+// @Produce json
+// @Param id path string true "user id"
+// @Param sort query string false "sort order [newest/helpful]"
+// @Param show_spoilers query string false "set true to show spoiler content"
+// @Param page query string false "page"
+// @Param limit query string false "limit"
+// @Success 200 {object} common.ResponseDTO{data=[]v1.Review{}}
+// @Failure 400 {object} common.ResponseDTO
+// @Router /api/v1/users/{id}/reviews [GET]
+func (u userApi) GetReviews(context echo.Context) error {
+	user, err := getPublicUser(context)
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: User Not Found!", err.Error())
+	}
+	sort, err := getReviewSort(context)
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: Invalid sort is provided", err.Error())
+	}
+	pagination := getPagination(context)
+	data, total := v1.Review{}.Search(v1.ExcludeUsersQuery(v1.UserReviewsQuery(user.ID), "reviewer_id", hiddenUserIds(context)), pagination, sort)
+	if !showSpoilers(context) {
+		for i := range data {
+			data[i] = data[i].MaskSpoilers()
+		}
+	}
+	metadata := getPaginationMetadataWithLinks(context, pagination, total, int64(len(data)), url.Values{"sort": {string(sort)}})
+	return common.GenerateSuccessResponse(context, data, &metadata, "Successful")
+}
+
+// GetComments... Get Comments Api
+// @Summary Get comments of user api
+// @Description Api for getting visible comments written by a user
+// @Tags User
+// @Produce json
+// @Param id path string true "user id"
+// @Param sort query string false "sort order [oldest/newest], newest by default"
+// @Param show_spoilers query string false "set true to show spoiler content"
+// @Param page query string false "page"
+// @Param limit query string false "limit"
+// @Success 200 {object} common.ResponseDTO{data=[]v1.Comment{}}
+// @Failure 400 {object} common.ResponseDTO
+// @Router /api/v1/users/{id}/comments [GET]
+func (u userApi) GetComments(context echo.Context) error {
+	user, err := getPublicUser(context)
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: User Not Found!", err.Error())
+	}
+	sort := enums.NEWEST_FIRST
+	if context.QueryParam("sort") != "" {
+		sort, err = getCommentSort(context)
+		if err != nil {
+			return common.GenerateErrorResponse(context, "[ERROR]: Invalid sort is provided", err.Error())
+		}
+	}
+	pagination := getPagination(context)
+	data, total := v1.Comment{}.Search(v1.ExcludeUsersQuery(v1.UserCommentsQuery(user.ID), "commenter_id", hiddenUserIds(context)), pagination, sort)
+	if !showSpoilers(context) {
+		for i := range data {
+			data[i] = data[i].MaskSpoilers()
+		}
+	}
+	metadata := getPaginationMetadataWithLinks(context, pagination, total, int64(len(data)), url.Values{"sort": {string(sort)}})
+	return common.GenerateSuccessResponse(context, data, &metadata, "Successful")
+}
+
+// getPublicUser returns the user of the id path param if the user has a public presence.
+func getPublicUser(context echo.Context) (v1.User, error) {
+	user := v1.User{}.GetByID(context.Param("id"))
+	if user.ID == "" || user.Status != enums.ACTIVE {
+		return v1.User{}, errors.New("please give a valid user id")
+	}
+	return user, nil
 }
 
 // Update... Update Api
@@ -179,10 +740,12 @@ func (u userApi) registerUser(context echo.Context) error {
 // @Tags User
 // @Produce json
 // @Param Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
-// @Param action path string true "action type [reset_password/update_status]"
+// @Param action path string true "action type [reset_password/update_status/update_preferences/update_role]"
 // @Param status path string false "status type [inactive/active] if action update_status"
-// @Param id path string false "updating users id, if action update_status"
+// @Param role path string false "role type [ADMIN/USER] if action update_role"
+// @Param id path string false "updating users id, if action update_status or update_role"
 // @Param password_reset_dto body v1.PasswordResetDto true "dto for resetting users password"
+// @Param preferences body v1.UserPreferences false "users preferences, if action update_preferences"
 // @Success 200 {object} common.ResponseDTO
 // @Failure 400 {object} common.ResponseDTO
 // @Forbidden 403 {object} common.ResponseDTO
@@ -195,6 +758,10 @@ func (u userApi) Update(context echo.Context) error {
 		return u.ResetPassword(context)
 	} else if action == string(enums.UPDATE_STATUS) {
 		return u.UpdateStatus(context)
+	} else if action == string(enums.UPDATE_PREFERENCES) {
+		return u.UpdatePreferences(context)
+	} else if action == string(enums.UPDATE_ROLE) {
+		return u.UpdateRole(context)
 	}
 	return common.GenerateErrorResponse(context, "[ERROR]: Invalid type is provided!", "Please provide a valid action type!")
 }
@@ -229,6 +796,57 @@ func (u userApi) UpdateStatus(context echo.Context) error {
 	return common.GenerateSuccessResponse(context, nil, nil, "Operation Successful!")
 }
 
+func (u userApi) UpdateRole(context echo.Context) error {
+	userFromToken, err := GetUserTokenDtoFromBearerToken(context, v1.Jwt{})
+	if err != nil {
+		return common.GenerateErrorResponse(context, err.Error(), "Operation Failed!")
+	}
+	if userFromToken.Role != enums.SUPERADMIN {
+		return common.GenerateForbiddenResponse(context, "[ERROR]: Insufficient permission", "Operation Failed!")
+	}
+	role := enums.ROLE(context.QueryParam("role"))
+	if role != enums.ADMIN && role != enums.USER {
+		return common.GenerateErrorResponse(context, "[ERROR]: Invalid role!", "Please provide a valid role!")
+	}
+	userId := context.QueryParam("id")
+	user := v1.User{}.GetByID(userId)
+	if user.ID == "" || user.Status == enums.DELETED {
+		return common.GenerateErrorResponse(context, "[ERROR]: User not found!", "Please provide a valid user id!")
+	}
+	if user.Role == enums.SUPERADMIN {
+		return common.GenerateForbiddenResponse(context, "[ERROR]: Insufficient permission", "Role of a super admin can not be changed!")
+	}
+	if user.Role == role {
+		return common.GenerateSuccessResponse(context, nil, nil, "Nothing to update")
+	}
+	err = v1.User{}.UpdateRole(userId, role)
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: Failed to update role!", err.Error())
+	}
+	if err := (v1.TokenService{}).DeleteByUID(userId); err != nil {
+		log.Println("[ERROR] Failed to revoke tokens:", err.Error())
+	}
+	notify(user.ID, userFromToken.ID, enums.ROLE_CHANGE, "", "", "Your role is changed from "+string(user.Role)+" to "+string(role))
+	return common.GenerateSuccessResponse(context, nil, nil, "Operation Successful!")
+}
+
+func (u userApi) UpdatePreferences(context echo.Context) error {
+	userFromToken, err := GetUserTokenDtoFromBearerToken(context, v1.Jwt{})
+	if err != nil {
+		return common.GenerateErrorResponse(context, err.Error(), "Operation Failed!")
+	}
+	preferences := v1.UserPreferences{}
+	if err := context.Bind(&preferences); err != nil {
+		log.Println("Input Error:", err.Error())
+		return common.GenerateErrorResponse(context, nil, "Failed to Bind Input!")
+	}
+	err = v1.User{}.UpdatePreferences(userFromToken.ID, preferences)
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: Failed to update preferences!", err.Error())
+	}
+	return common.GenerateSuccessResponse(context, preferences, nil, "Operation Successful!")
+}
+
 func (u userApi) ResetPassword(context echo.Context) error {
 	formData := v1.PasswordResetDto{}
 	if err := context.Bind(&formData); err != nil {
@@ -238,16 +856,11 @@ func (u userApi) ResetPassword(context echo.Context) error {
 	if formData.CurrentPassword == "" {
 		return common.GenerateForbiddenResponse(context, "[ERROR]: Failed to reset password!", "Please provide required data!")
 	}
-	var user v1.User
-	if formData.Email == "" {
-		userFromToken, err := GetUserTokenDtoFromBearerToken(context, v1.Jwt{})
-		if err != nil {
-			return common.GenerateErrorResponse(context, err.Error(), "Operation Failed!")
-		}
-		user = v1.User{}.GetByID(userFromToken.ID)
-	} else {
-		user = v1.User{}.GetByEmail(formData.Email)
+	userFromToken, err := GetUserTokenDtoFromBearerToken(context, v1.Jwt{})
+	if err != nil {
+		return common.GenerateErrorResponse(context, err.Error(), "Operation Failed!")
 	}
+	user := v1.User{}.GetByID(userFromToken.ID)
 	if user.ID == "" || user.Status != enums.ACTIVE {
 		return common.GenerateForbiddenResponse(context, "[ERROR]: No User found!", "Please login with actual user email!")
 	}
@@ -258,7 +871,7 @@ func (u userApi) ResetPassword(context echo.Context) error {
 		}
 	}
 	user.Password = formData.NewPassword
-	err := v1.User{}.UpdatePassword(user)
+	err = v1.User{}.UpdatePassword(user)
 	if err != nil {
 		return common.GenerateForbiddenResponse(context, "[ERROR]: Failed to reset password!", err.Error())
 	}
@@ -296,5 +909,8 @@ func (u userApi) Delete(context echo.Context) error {
 	if err != nil {
 		return common.GenerateErrorResponse(context, nil, "Failed to Delete User!")
 	}
+	if err := (v1.DataExport{}).DeleteByUserId(id, v1.NewBlobStore()); err != nil {
+		log.Println("[ERROR] Failed to delete data exports:", err.Error())
+	}
 	return common.GenerateSuccessResponse(context, nil, nil, "Successfully Deleted User!")
 }
@@ -5,11 +5,15 @@ import (
 	"errors"
 	"github.com/golang-jwt/jwt"
 	"github.com/labstack/echo/v4"
+	"github.com/niloydeb1/Golang-Movie_API/api/common"
 	"github.com/niloydeb1/Golang-Movie_API/config"
+	"github.com/niloydeb1/Golang-Movie_API/enums"
 	v1 "github.com/niloydeb1/Golang-Movie_API/src/v1"
 	"log"
+	"net/url"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // GetUserFromBearerToken returns user from bearer token
@@ -27,6 +31,9 @@ func GetUserTokenDtoFromBearerToken(context echo.Context, jwtService v1.Jwt) (v1
 	if !jwtService.IsTokenValid(token) {
 		return v1.UserTokenDto{}, errors.New("[ERROR]: Token is expired!")
 	}
+	if (v1.TokenService{}).GetByToken(token).Uid == "" {
+		return v1.UserTokenDto{}, errors.New("[ERROR]: Token is revoked!")
+	}
 	claims := jwt.MapClaims{}
 	jwt.ParseWithClaims(token, claims, func(token *jwt.Token) (interface{}, error) {
 		return []byte(config.Publickey), nil
@@ -42,6 +49,56 @@ func GetUserTokenDtoFromBearerToken(context echo.Context, jwtService v1.Jwt) (v1
 	return userTokenDto, nil
 }
 
+// getOptionalUserTokenDto returns user from bearer token if the request carries a valid one.
+func getOptionalUserTokenDto(context echo.Context) (v1.UserTokenDto, bool) {
+	if context.Request().Header.Get("Authorization") == "" {
+		return v1.UserTokenDto{}, false
+	}
+	userTokenDto, err := GetUserTokenDtoFromBearerToken(context, v1.Jwt{})
+	if err != nil || userTokenDto.ID == "" {
+		return v1.UserTokenDto{}, false
+	}
+	return userTokenDto, true
+}
+
+// hiddenUserIds returns ids of users whose content is hidden from the requesting user, because
+// the requesting user blocked or muted them or they blocked the requesting user.
+func hiddenUserIds(context echo.Context) []string {
+	userFromToken, ok := getOptionalUserTokenDto(context)
+	if !ok {
+		return nil
+	}
+	return v1.UserRelation{}.HiddenUserIds(userFromToken.ID)
+}
+
+// isAdmin returns true if the user is an admin or superadmin.
+func isAdmin(userTokenDto v1.UserTokenDto) bool {
+	return userTokenDto.Role == enums.ADMIN || userTokenDto.Role == enums.SUPERADMIN
+}
+
+// canSeeModeratedContent returns true if the requesting user may see content of the author
+// that is not visible to everyone, which is the case for the author and admins.
+func canSeeModeratedContent(context echo.Context, authorId string) bool {
+	userFromToken, ok := getOptionalUserTokenDto(context)
+	if !ok {
+		return false
+	}
+	return userFromToken.ID == authorId || isAdmin(userFromToken)
+}
+
+// showSpoilers returns true if spoiler content should not be masked, either because the client
+// asked for it with show_spoilers=true or the user opted in through preferences.
+func showSpoilers(context echo.Context) bool {
+	if show := context.QueryParam("show_spoilers"); show != "" {
+		return show == "true"
+	}
+	userFromToken, ok := getOptionalUserTokenDto(context)
+	if !ok {
+		return false
+	}
+	return v1.User{}.GetByID(userFromToken.ID).Preferences.ShowSpoilers
+}
+
 func getPagination(context echo.Context) v1.Pagination {
 	option := v1.Pagination{}
 	page := context.QueryParam("page")
@@ -55,3 +112,157 @@ func getPagination(context echo.Context) v1.Pagination {
 	}
 	return option
 }
+
+// getReviewSort returns review sort order from query param, newest first by default.
+func getReviewSort(context echo.Context) (enums.REVIEW_SORT, error) {
+	sort := enums.REVIEW_SORT(context.QueryParam("sort"))
+	switch sort {
+	case "":
+		return enums.NEWEST, nil
+	case enums.NEWEST, enums.HELPFUL:
+		return sort, nil
+	}
+	return "", errors.New("sort must be one of [newest/helpful]")
+}
+
+// getCommentSort returns the comment sort order of the request, oldest first by default.
+func getCommentSort(context echo.Context) (enums.COMMENT_SORT, error) {
+	sort := enums.COMMENT_SORT(context.QueryParam("sort"))
+	switch sort {
+	case "":
+		return enums.OLDEST_FIRST, nil
+	case enums.OLDEST_FIRST, enums.NEWEST_FIRST:
+		return sort, nil
+	}
+	return "", errors.New("sort must be one of [oldest/newest]")
+}
+
+// getWatchlistSort returns the watchlist sort order of the request, recently added first by default.
+func getWatchlistSort(context echo.Context) (enums.WATCHLIST_SORT, error) {
+	sort := enums.WATCHLIST_SORT(context.QueryParam("sort"))
+	switch sort {
+	case "":
+		return enums.RECENTLY_ADDED, nil
+	case enums.RECENTLY_ADDED, enums.RELEASE_YEAR, enums.TOP_RATED:
+		return sort, nil
+	}
+	return "", errors.New("sort must be one of [added/year/rating]")
+}
+
+// getListSort returns the movie list sort order of the request, recently updated first by default.
+func getListSort(context echo.Context) (enums.LIST_SORT, error) {
+	sort := enums.LIST_SORT(context.QueryParam("sort"))
+	switch sort {
+	case "":
+		return enums.RECENTLY_UPDATED, nil
+	case enums.RECENTLY_UPDATED, enums.RECENTLY_CREATED, enums.RECENTLY_FEATURED:
+		return sort, nil
+	}
+	return "", errors.New("sort must be one of [updated/created/featured]")
+}
+
+// getUserSort returns the user directory sort order of the request, newest first by default.
+func getUserSort(context echo.Context) (enums.USER_SORT, error) {
+	sort := enums.USER_SORT(context.QueryParam("sort"))
+	switch sort {
+	case "":
+		return enums.USERS_NEWEST, nil
+	case enums.USERS_NEWEST, enums.USERS_OLDEST, enums.USERS_BY_NAME, enums.USERS_BY_EMAIL:
+		return sort, nil
+	}
+	return "", errors.New("sort must be one of [newest/oldest/name/email]")
+}
+
+// getUserFilter returns the user directory filter of the request.
+func getUserFilter(context echo.Context) (v1.UserFilter, error) {
+	filter := v1.UserFilter{
+		Search: strings.TrimSpace(context.QueryParam("q")),
+		Role:   enums.ROLE(strings.ToUpper(context.QueryParam("role"))),
+		Status: enums.STATUS(context.QueryParam("status")),
+	}
+	switch filter.Role {
+	case "", enums.SUPERADMIN, enums.ADMIN, enums.USER:
+	default:
+		return v1.UserFilter{}, errors.New("role must be one of [SUPERADMIN/ADMIN/USER]")
+	}
+	switch filter.Status {
+	case "", enums.ACTIVE, enums.INACTIVE, enums.DELETED, enums.PENDING_VERIFICATION:
+	default:
+		return v1.UserFilter{}, errors.New("status must be one of [active/inactive/deleted/pending_verification]")
+	}
+	var err error
+	if filter.CreatedFrom, err = parseDateParam(context.QueryParam("created_from"), false); err != nil {
+		return v1.UserFilter{}, errors.New("created_from " + err.Error())
+	}
+	if filter.CreatedTo, err = parseDateParam(context.QueryParam("created_to"), true); err != nil {
+		return v1.UserFilter{}, errors.New("created_to " + err.Error())
+	}
+	if !filter.CreatedFrom.IsZero() && !filter.CreatedTo.IsZero() && !filter.CreatedFrom.Before(filter.CreatedTo) {
+		return v1.UserFilter{}, errors.New("created_from must be before created_to")
+	}
+	return filter, nil
+}
+
+// parseDateParam parses an RFC3339 time or a YYYY-MM-DD date in UTC. A date used as exclusive
+// end of a range is moved to the start of the next day, so the whole day is included.
+func parseDateParam(value string, end bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.UTC(), nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, errors.New("must be an RFC3339 time or a YYYY-MM-DD date")
+	}
+	if end {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
+
+// filterContent runs the content filter pipeline on the fields and records its decisions.
+// Masked text is written back to the fields. It returns an error if a filter rejected the content.
+func filterContent(contentType enums.CONTENT_TYPE, targetId, authorId string, fields ...v1.FilterField) (v1.ContentFilterResult, error) {
+	result := v1.NewContentFilterPipeline().Run(contentType, targetId, authorId, fields...)
+	if err := (v1.FilterDecision{}).StoreMany(result.Decisions); err != nil {
+		log.Println("[ERROR] Failed to record content filter decisions:", err.Error())
+	}
+	if result.Rejected {
+		return result, errors.New(result.Reason(enums.REJECT))
+	}
+	return result, nil
+}
+
+// flaggedModeration returns moderation of content flagged by content filter.
+func flaggedModeration(result v1.ContentFilterResult) v1.Moderation {
+	return v1.Moderation{
+		State:  enums.PENDING,
+		Reason: "flagged by content filter: " + result.Reason(enums.FLAG),
+	}
+}
+
+// getPaginationMetadataWithLinks returns pagination metadata with prev, self and next links.
+// Query values other than page and limit are kept in every link.
+func getPaginationMetadataWithLinks(context echo.Context, pagination v1.Pagination, total, count int64, query url.Values) common.MetaData {
+	metadata := common.GetPaginationMetadata(pagination.Page, pagination.Limit, total, count)
+	uri := strings.Split(context.Request().RequestURI, "?")[0]
+	link := func(page int64) string {
+		values := url.Values{}
+		for k, v := range query {
+			values[k] = v
+		}
+		values.Set("page", strconv.FormatInt(page, 10))
+		values.Set("limit", strconv.FormatInt(pagination.Limit, 10))
+		return uri + "?" + values.Encode()
+	}
+	if pagination.Page > 0 {
+		metadata.Links = append(metadata.Links, map[string]string{"prev": link(pagination.Page - 1)})
+	}
+	metadata.Links = append(metadata.Links, map[string]string{"self": link(pagination.Page)})
+	if (pagination.Page+1)*pagination.Limit < metadata.TotalCount {
+		metadata.Links = append(metadata.Links, map[string]string{"next": link(pagination.Page + 1)})
+	}
+	return metadata
+}
@@ -0,0 +1,172 @@
+package v1
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/niloydeb1/Golang-Movie_API/api/common"
+	"github.com/niloydeb1/Golang-Movie_API/enums"
+	v1 "github.com/niloydeb1/Golang-Movie_API/src/v1"
+	"log"
+	"net/url"
+	"time"
+)
+
+type watchlistApi struct {
+}
+
+// Get... Get Api
+// @Summary Get own watchlist api
+// @Description Api for getting movies on own watchlist
+// @Tags Watchlist
+// @Produce json
+// @Param Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param sort query string false "sort order [added/year/rating]"
+// @Param priority query string false "priority [low/normal/high]"
+// @Param page query string false "page"
+// @Param limit query string false "limit"
+// @Success 200 {object} common.ResponseDTO{data=[]v1.WatchlistEntry{}}
+// @Forbidden 403 {object} common.ResponseDTO
+// @Failure 400 {object} common.ResponseDTO
+// @Router /api/v1/users/me/watchlist [GET]
+func (w watchlistApi) Get(context echo.Context) error {
+	userFromToken, err := GetUserTokenDtoFromBearerToken(context, v1.Jwt{})
+	if err != nil {
+		return common.GenerateErrorResponse(context, err.Error(), "Operation Failed!")
+	}
+	sort, err := getWatchlistSort(context)
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: Invalid sort is provided", err.Error())
+	}
+	priority := enums.WATCHLIST_PRIORITY(context.QueryParam("priority"))
+	if priority != "" {
+		if err := v1.ValidateWatchlistPriority(priority); err != nil {
+			return common.GenerateErrorResponse(context, "[ERROR]: Invalid priority is provided", err.Error())
+		}
+	}
+	pagination := getPagination(context)
+	data, total := v1.WatchlistEntry{}.GetByUserId(userFromToken.ID, priority, pagination, sort)
+	query := url.Values{"sort": {string(sort)}}
+	if priority != "" {
+		query.Set("priority", string(priority))
+	}
+	metadata := getPaginationMetadataWithLinks(context, pagination, total, int64(len(data)), query)
+	return common.GenerateSuccessResponse(context, data, &metadata, "Successful")
+}
+
+// Post... Post Api
+// @Summary Add to own watchlist api
+// @Description Api for adding a movie to own watchlist by movie id or title. A movie that is not stored yet is fetched by title
+// @Tags Watchlist
+// @Produce json
+// @Param Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param data body v1.WatchlistEntryDto true "dto for adding a watchlist entry"
+// @Success 200 {object} common.ResponseDTO{data=v1.WatchlistEntry{}}
+// @Forbidden 403 {object} common.ResponseDTO
+// @Failure 400 {object} common.ResponseDTO
+// @Failure 409 {object} common.ResponseDTO
+// @Router /api/v1/users/me/watchlist [POST]
+func (w watchlistApi) Post(context echo.Context) error {
+	userFromToken, err := GetUserTokenDtoFromBearerToken(context, v1.Jwt{})
+	if err != nil {
+		return common.GenerateErrorResponse(context, err.Error(), "Operation Failed!")
+	}
+	formData := v1.WatchlistEntryDto{}
+	if err := context.Bind(&formData); err != nil {
+		log.Println("Input Error:", err.Error())
+		return common.GenerateErrorResponse(context, nil, "Failed to Bind Input!")
+	}
+	err = formData.Validate()
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: Invalid data provided", err.Error())
+	}
+	var movie v1.Movie
+	if formData.MovieId != "" {
+		movie = v1.Movie{}.GetByID(formData.MovieId)
+		if movie.ID == "" {
+			err = errors.New("movie does not exist")
+		}
+	} else {
+		movie, err = getOrFetchMovie(formData.Title)
+	}
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: Movie is not found", err.Error())
+	}
+	if formData.Priority == "" {
+		formData.Priority = enums.NORMAL_PRIORITY
+	}
+	now := time.Now().UTC()
+	entry := v1.WatchlistEntry{
+		ID:        uuid.New().String(),
+		UserId:    userFromToken.ID,
+		Movie:     v1.NewMovieSummary(movie),
+		Priority:  formData.Priority,
+		Notes:     formData.Notes,
+		AddedAt:   now,
+		UpdatedAt: now,
+	}
+	err = v1.WatchlistEntry{}.Store(entry)
+	if err == v1.ErrWatchlistEntryExists {
+		return common.GenerateConflictResponse(context, v1.WatchlistEntry{}.GetByUserAndMovie(userFromToken.ID, movie.ID), "Movie is already on your watchlist!")
+	}
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: Failed to add movie to watchlist!", err.Error())
+	}
+	return common.GenerateSuccessResponse(context, entry, nil, "Operation Successful")
+}
+
+// Patch... Patch Api
+// @Summary Update own watchlist entry api
+// @Description Api for updating priority or notes of a movie on own watchlist
+// @Tags Watchlist
+// @Produce json
+// @Param Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param movie_id path string true "movie id"
+// @Param data body v1.WatchlistUpdateDto true "dto for updating a watchlist entry, omitted fields are left unchanged"
+// @Success 200 {object} common.ResponseDTO{data=v1.WatchlistEntry{}}
+// @Forbidden 403 {object} common.ResponseDTO
+// @Failure 400 {object} common.ResponseDTO
+// @Router /api/v1/users/me/watchlist/{movie_id} [PATCH]
+func (w watchlistApi) Patch(context echo.Context) error {
+	userFromToken, err := GetUserTokenDtoFromBearerToken(context, v1.Jwt{})
+	if err != nil {
+		return common.GenerateErrorResponse(context, err.Error(), "Operation Failed!")
+	}
+	formData := v1.WatchlistUpdateDto{}
+	if err := context.Bind(&formData); err != nil {
+		log.Println("Input Error:", err.Error())
+		return common.GenerateErrorResponse(context, nil, "Failed to Bind Input!")
+	}
+	err = formData.Validate()
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: Invalid data provided", err.Error())
+	}
+	entry, err := v1.WatchlistEntry{}.Update(userFromToken.ID, context.Param("movie_id"), formData)
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: Failed to update watchlist entry!", err.Error())
+	}
+	return common.GenerateSuccessResponse(context, entry, nil, "Operation Successful")
+}
+
+// Delete... Delete Api
+// @Summary Remove from own watchlist api
+// @Description Api for removing a movie from own watchlist
+// @Tags Watchlist
+// @Produce json
+// @Param Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param movie_id path string true "movie id"
+// @Success 200 {object} common.ResponseDTO
+// @Forbidden 403 {object} common.ResponseDTO
+// @Failure 400 {object} common.ResponseDTO
+// @Router /api/v1/users/me/watchlist/{movie_id} [DELETE]
+func (w watchlistApi) Delete(context echo.Context) error {
+	userFromToken, err := GetUserTokenDtoFromBearerToken(context, v1.Jwt{})
+	if err != nil {
+		return common.GenerateErrorResponse(context, err.Error(), "Operation Failed!")
+	}
+	err = v1.WatchlistEntry{}.Delete(userFromToken.ID, context.Param("movie_id"))
+	if err != nil {
+		return common.GenerateErrorResponse(context, "[ERROR]: Failed to remove movie from watchlist!", err.Error())
+	}
+	return common.GenerateSuccessResponse(context, "[SUCCESS]: Movie is removed from watchlist", nil, "Operation Successful")
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/niloydeb1/Golang-Movie_API/enums"
 	"log"
 	"os"
+	"strconv"
 	"strings"
 )
 
@@ -62,6 +63,93 @@ var RunMode string
 // EnableOpenTracing set true if opentracing is needed.
 var EnableOpenTracing bool
 
+// ModerationPolicy refers to policy of pre-moderating reviews and comments.
+var ModerationPolicy enums.MODERATION_POLICY
+
+// ModerationNewAccountDays refers to number of days an account is considered new by moderation policy.
+var ModerationNewAccountDays int64
+
+// ContentFilterWords refers to words blocked by content filter.
+var ContentFilterWords []string
+
+// ContentFilterWordAction refers to action taken on content containing blocked words.
+var ContentFilterWordAction enums.FILTER_ACTION
+
+// ContentFilterMaxLinks refers to maximum number of links allowed before content is considered spam.
+var ContentFilterMaxLinks int64
+
+// ContentFilterDuplicateWindowHours refers to hours within which reposting the same text is considered spam.
+var ContentFilterDuplicateWindowHours int64
+
+// ContentFilterSpamAction refers to action taken on content considered spam.
+var ContentFilterSpamAction enums.FILTER_ACTION
+
+// ContentFilterMaxLength refers to maximum length of a review or comment text.
+var ContentFilterMaxLength int64
+
+// ReportAutoHideThreshold refers to number of distinct reports that hides content until it is reviewed, 0 disables it.
+var ReportAutoHideThreshold int64
+
+// CommentMaxDepth refers to maximum nesting depth of comment replies.
+var CommentMaxDepth int64
+
+// CommentEditWindowMinutes refers to minutes after posting within which the author can edit a comment.
+var CommentEditWindowMinutes int64
+
+// ReactionEmojis refers to the emojis users can react with on reviews and comments.
+var ReactionEmojis []string
+
+// ApiBaseUrl refers to public base url of the api, used in links sent by mail.
+var ApiBaseUrl string
+
+// Mailer refers to mail delivery implementation [smtp/log].
+var Mailer enums.MAILER
+
+// SmtpHost refers to smtp server host.
+var SmtpHost string
+
+// SmtpPort refers to smtp server port.
+var SmtpPort string
+
+// SmtpUsername refers to smtp username, empty if the server needs no authentication.
+var SmtpUsername string
+
+// SmtpPassword refers to smtp password.
+var SmtpPassword string
+
+// MailFrom refers to sender address of mails.
+var MailFrom string
+
+// MailLogPath refers to file mails are appended to by log mailer, empty to write them to log.
+var MailLogPath string
+
+// EmailVerificationTokenLifetimeHours refers to hours an email verification link is valid.
+var EmailVerificationTokenLifetimeHours int64
+
+// VerificationMailResendIntervalSeconds refers to seconds a user has to wait before another verification mail is sent.
+var VerificationMailResendIntervalSeconds int64
+
+// VerificationMailDailyLimit refers to maximum number of verification mails sent to a user within a day.
+var VerificationMailDailyLimit int64
+
+// PasswordResetTokenLifetimeMinutes refers to minutes a password reset token is valid.
+var PasswordResetTokenLifetimeMinutes int64
+
+// BlobStorePath refers to directory blobs such as avatars are stored in.
+var BlobStorePath string
+
+// AvatarMaxBytes refers to maximum size of an uploaded avatar.
+var AvatarMaxBytes int64
+
+// AvatarSize refers to width and height avatars are resized to.
+var AvatarSize int64
+
+// DataExportLifetimeHours refers to hours a personal data export can be downloaded after it is built.
+var DataExportLifetimeHours int64
+
+// DataExportCleanupIntervalMinutes refers to minutes between removals of expired data export archives.
+var DataExportCleanupIntervalMinutes int64
+
 // InitEnvironmentVariables initializes environment variables
 func InitEnvironmentVariables() {
 	RunMode = os.Getenv("RUN_MODE")
@@ -102,6 +190,50 @@ func InitEnvironmentVariables() {
 	Email = os.Getenv("USER_EMAIL")
 	Password = os.Getenv("USER_PASSWORD")
 	PhoneNumber = os.Getenv("USER_PHONE")
+	ModerationPolicy = enums.MODERATION_POLICY(strings.ToLower(os.Getenv("MODERATION_POLICY")))
+	if ModerationPolicy != enums.MODERATE_NEW_ACCOUNTS && ModerationPolicy != enums.MODERATE_ALL {
+		ModerationPolicy = enums.MODERATE_NONE
+	}
+	ModerationNewAccountDays = getInt64Env("MODERATION_NEW_ACCOUNT_DAYS", 7)
+	ContentFilterWords = getStringListEnv("CONTENT_FILTER_WORDS")
+	ContentFilterWordAction = getFilterActionEnv("CONTENT_FILTER_WORD_ACTION", enums.MASK)
+	ContentFilterMaxLinks = getInt64Env("CONTENT_FILTER_MAX_LINKS", 3)
+	ContentFilterDuplicateWindowHours = getInt64Env("CONTENT_FILTER_DUPLICATE_WINDOW_HOURS", 24)
+	ContentFilterSpamAction = getFilterActionEnv("CONTENT_FILTER_SPAM_ACTION", enums.FLAG)
+	ContentFilterMaxLength = getInt64Env("CONTENT_FILTER_MAX_LENGTH", 5000)
+	ReportAutoHideThreshold = getInt64Env("REPORT_AUTO_HIDE_THRESHOLD", 3)
+	CommentMaxDepth = getInt64Env("COMMENT_MAX_DEPTH", 5)
+	CommentEditWindowMinutes = getInt64Env("COMMENT_EDIT_WINDOW_MINUTES", 15)
+	ReactionEmojis = getStringListEnv("REACTION_EMOJIS")
+	if len(ReactionEmojis) == 0 {
+		ReactionEmojis = []string{"👍", "❤️", "😂", "😮", "😢", "😡"}
+	}
+	ApiBaseUrl = strings.TrimSuffix(os.Getenv("API_BASE_URL"), "/")
+	if ApiBaseUrl == "" {
+		ApiBaseUrl = "http://localhost:" + ServerPort
+	}
+	Mailer = enums.MAILER(strings.ToLower(os.Getenv("MAILER")))
+	if Mailer != enums.SMTP_MAILER {
+		Mailer = enums.LOG_MAILER
+	}
+	SmtpHost = os.Getenv("SMTP_HOST")
+	SmtpPort = os.Getenv("SMTP_PORT")
+	SmtpUsername = os.Getenv("SMTP_USERNAME")
+	SmtpPassword = os.Getenv("SMTP_PASSWORD")
+	MailFrom = os.Getenv("MAIL_FROM")
+	MailLogPath = os.Getenv("MAIL_LOG_PATH")
+	EmailVerificationTokenLifetimeHours = getInt64Env("EMAIL_VERIFICATION_TOKEN_LIFETIME_HOURS", 24)
+	VerificationMailResendIntervalSeconds = getInt64Env("VERIFICATION_MAIL_RESEND_INTERVAL_SECONDS", 60)
+	VerificationMailDailyLimit = getInt64Env("VERIFICATION_MAIL_DAILY_LIMIT", 5)
+	PasswordResetTokenLifetimeMinutes = getInt64Env("PASSWORD_RESET_TOKEN_LIFETIME_MINUTES", 30)
+	BlobStorePath = os.Getenv("BLOB_STORE_PATH")
+	if BlobStorePath == "" {
+		BlobStorePath = "data/blobs"
+	}
+	AvatarMaxBytes = getInt64Env("AVATAR_MAX_BYTES", 2*1024*1024)
+	AvatarSize = getInt64Env("AVATAR_SIZE", 256)
+	DataExportLifetimeHours = getInt64Env("DATA_EXPORT_LIFETIME_HOURS", 48)
+	DataExportCleanupIntervalMinutes = getInt64Env("DATA_EXPORT_CLEANUP_INTERVAL_MINUTES", 60)
 	if os.Getenv("ENABLE_OPENTRACING") == "" {
 		EnableOpenTracing = false
 	} else {
@@ -112,3 +244,33 @@ func InitEnvironmentVariables() {
 		}
 	}
 }
+
+// getInt64Env returns integer value of environment variable, or the default if it is unset or invalid.
+func getInt64Env(key string, defaultValue int64) int64 {
+	value, err := strconv.ParseInt(os.Getenv(key), 10, 64)
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+// getStringListEnv returns comma separated values of environment variable.
+func getStringListEnv(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		value = strings.TrimSpace(value)
+		if value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// getFilterActionEnv returns content filter action of environment variable, or the default if it is unset or invalid.
+func getFilterActionEnv(key string, defaultValue enums.FILTER_ACTION) enums.FILTER_ACTION {
+	action := enums.FILTER_ACTION(strings.ToLower(os.Getenv(key)))
+	if action != enums.REJECT && action != enums.FLAG && action != enums.MASK {
+		return defaultValue
+	}
+	return action
+}
@@ -27,6 +27,10 @@ const (
 	RESET_PASSWORD = USER_UPDATE_ACTION("reset_password")
 	// UPDATE_STATUS refers to status update action
 	UPDATE_STATUS = USER_UPDATE_ACTION("update_status")
+	// UPDATE_PREFERENCES refers to preferences update action
+	UPDATE_PREFERENCES = USER_UPDATE_ACTION("update_preferences")
+	// UPDATE_ROLE refers to role update action
+	UPDATE_ROLE = USER_UPDATE_ACTION("update_role")
 )
 
 // STATUS status update action
@@ -39,6 +43,30 @@ const (
 	INACTIVE = STATUS("inactive")
 	// DELETED user status for deleted user
 	DELETED = STATUS("deleted")
+	// PENDING_VERIFICATION user status for user whose email is not verified yet
+	PENDING_VERIFICATION = STATUS("pending_verification")
+)
+
+// TOKEN_SCOPE scope of a signed single purpose token
+type TOKEN_SCOPE string
+
+const (
+	// EMAIL_VERIFICATION refers to token verifying ownership of an email
+	EMAIL_VERIFICATION = TOKEN_SCOPE("email_verification")
+	// EMAIL_CHANGE refers to token verifying ownership of a new email of a user
+	EMAIL_CHANGE = TOKEN_SCOPE("email_change")
+	// DATA_EXPORT refers to token granting download of a personal data export
+	DATA_EXPORT = TOKEN_SCOPE("data_export")
+)
+
+// MAILER mail delivery implementation
+type MAILER string
+
+const (
+	// SMTP_MAILER refers to delivery through an smtp server
+	SMTP_MAILER = MAILER("smtp")
+	// LOG_MAILER refers to writing mails to log or a file, for local use
+	LOG_MAILER = MAILER("log")
 )
 
 // USER_REGISTRATION_ACTION user registration action
@@ -59,4 +87,212 @@ const (
 	ADMIN = ROLE("ADMIN")
 	// USER refers to user role
 	USER = ROLE("USER")
-)
\ No newline at end of file
+)
+
+// REVIEW_SORT review listing sort order
+type REVIEW_SORT string
+
+const (
+	// NEWEST refers to newest reviews first
+	NEWEST = REVIEW_SORT("newest")
+	// HELPFUL refers to most helpful reviews first
+	HELPFUL = REVIEW_SORT("helpful")
+)
+
+// COMMENT_SORT comment listing sort order
+type COMMENT_SORT string
+
+const (
+	// OLDEST_FIRST refers to oldest comments first
+	OLDEST_FIRST = COMMENT_SORT("oldest")
+	// NEWEST_FIRST refers to newest comments first
+	NEWEST_FIRST = COMMENT_SORT("newest")
+)
+
+// USER_SORT user directory sort order
+type USER_SORT string
+
+const (
+	// USERS_NEWEST refers to most recently registered users first
+	USERS_NEWEST = USER_SORT("newest")
+	// USERS_OLDEST refers to earliest registered users first
+	USERS_OLDEST = USER_SORT("oldest")
+	// USERS_BY_NAME refers to users ordered by first and last name
+	USERS_BY_NAME = USER_SORT("name")
+	// USERS_BY_EMAIL refers to users ordered by email
+	USERS_BY_EMAIL = USER_SORT("email")
+)
+
+// WATCHLIST_SORT watchlist listing sort order
+type WATCHLIST_SORT string
+
+const (
+	// RECENTLY_ADDED refers to most recently added entries first
+	RECENTLY_ADDED = WATCHLIST_SORT("added")
+	// RELEASE_YEAR refers to most recently released movies first
+	RELEASE_YEAR = WATCHLIST_SORT("year")
+	// TOP_RATED refers to highest rated movies first
+	TOP_RATED = WATCHLIST_SORT("rating")
+)
+
+// WATCHLIST_PRIORITY priority of a watchlist entry
+type WATCHLIST_PRIORITY string
+
+const (
+	// LOW_PRIORITY refers to a movie to watch some day
+	LOW_PRIORITY = WATCHLIST_PRIORITY("low")
+	// NORMAL_PRIORITY refers to a movie to watch
+	NORMAL_PRIORITY = WATCHLIST_PRIORITY("normal")
+	// HIGH_PRIORITY refers to a movie to watch next
+	HIGH_PRIORITY = WATCHLIST_PRIORITY("high")
+)
+
+// LIST_VISIBILITY visibility of a movie list
+type LIST_VISIBILITY string
+
+const (
+	// PUBLIC_LIST refers to a list shown to everyone and in list search
+	PUBLIC_LIST = LIST_VISIBILITY("public")
+	// UNLISTED_LIST refers to a list shown to anyone with its link, but not in list search
+	UNLISTED_LIST = LIST_VISIBILITY("unlisted")
+	// PRIVATE_LIST refers to a list shown only to its owner
+	PRIVATE_LIST = LIST_VISIBILITY("private")
+)
+
+// LIST_SORT movie list listing sort order
+type LIST_SORT string
+
+const (
+	// RECENTLY_UPDATED refers to most recently updated lists first
+	RECENTLY_UPDATED = LIST_SORT("updated")
+	// RECENTLY_CREATED refers to most recently created lists first
+	RECENTLY_CREATED = LIST_SORT("created")
+	// RECENTLY_FEATURED refers to most recently featured lists first
+	RECENTLY_FEATURED = LIST_SORT("featured")
+)
+
+// ACTIVITY_TYPE type of user activity shown in feeds
+type ACTIVITY_TYPE string
+
+const (
+	// REVIEW_POSTED refers to a review posted by a user
+	REVIEW_POSTED = ACTIVITY_TYPE("review")
+	// COMMENT_POSTED refers to a comment posted by a user
+	COMMENT_POSTED = ACTIVITY_TYPE("comment")
+	// DIARY_LOGGED refers to a viewing logged to a users diary
+	DIARY_LOGGED = ACTIVITY_TYPE("diary_entry")
+	// LIST_CREATED refers to a public movie list created by a user
+	LIST_CREATED = ACTIVITY_TYPE("list")
+)
+
+// RELATION_TYPE type of a relation a user sets on another user
+type RELATION_TYPE string
+
+const (
+	// BLOCK refers to a user that can not interact with the blocker
+	BLOCK = RELATION_TYPE("block")
+	// MUTE refers to a user whose content is hidden from the muter
+	MUTE = RELATION_TYPE("mute")
+)
+
+// MODERATION_STATE moderation state of reviews and comments
+type MODERATION_STATE string
+
+const (
+	// PENDING refers to content waiting for moderation
+	PENDING = MODERATION_STATE("pending")
+	// APPROVED refers to content visible to everyone
+	APPROVED = MODERATION_STATE("approved")
+	// REJECTED refers to content rejected by a moderator
+	REJECTED = MODERATION_STATE("rejected")
+	// HIDDEN refers to content hidden from everyone but its author
+	HIDDEN = MODERATION_STATE("hidden")
+)
+
+// MODERATION_POLICY policy that decides which content is moderated before going live
+type MODERATION_POLICY string
+
+const (
+	// MODERATE_NONE refers to publishing all content immediately
+	MODERATE_NONE = MODERATION_POLICY("none")
+	// MODERATE_NEW_ACCOUNTS refers to pre-moderating content of new accounts
+	MODERATE_NEW_ACCOUNTS = MODERATION_POLICY("new_accounts")
+	// MODERATE_ALL refers to pre-moderating all content
+	MODERATE_ALL = MODERATION_POLICY("all")
+)
+
+// CONTENT_TYPE type of user generated content
+type CONTENT_TYPE string
+
+const (
+	// REVIEW refers to review content
+	REVIEW = CONTENT_TYPE("review")
+	// COMMENT refers to comment content
+	COMMENT = CONTENT_TYPE("comment")
+	// LIST refers to movie list content
+	LIST = CONTENT_TYPE("list")
+)
+
+// NOTIFICATION_TYPE type of user notification
+type NOTIFICATION_TYPE string
+
+const (
+	// MENTION refers to a user mentioned in a review or comment
+	MENTION = NOTIFICATION_TYPE("mention")
+	// REVIEW_COMMENT refers to a comment on a users review
+	REVIEW_COMMENT = NOTIFICATION_TYPE("review_comment")
+	// COMMENT_REPLY refers to a reply to a users comment
+	COMMENT_REPLY = NOTIFICATION_TYPE("comment_reply")
+	// MODERATION_DECISION refers to a moderation decision on a users review or comment
+	MODERATION_DECISION = NOTIFICATION_TYPE("moderation_decision")
+	// ROLE_CHANGE refers to a change of a users role
+	ROLE_CHANGE = NOTIFICATION_TYPE("role_change")
+	// LIST_COMMENT refers to a comment on a users movie list
+	LIST_COMMENT = NOTIFICATION_TYPE("list_comment")
+)
+
+// FILTER_ACTION action taken by a content filter
+type FILTER_ACTION string
+
+const (
+	// REJECT refers to refusing the content
+	REJECT = FILTER_ACTION("reject")
+	// FLAG refers to sending the content to moderation queue
+	FLAG = FILTER_ACTION("flag")
+	// MASK refers to masking the offending part of the content
+	MASK = FILTER_ACTION("mask")
+)
+
+// REPORT_REASON reason category of a content report
+type REPORT_REASON string
+
+const (
+	// SPAM refers to spam or advertising
+	SPAM = REPORT_REASON("spam")
+	// HARASSMENT refers to harassment or bullying
+	HARASSMENT = REPORT_REASON("harassment")
+	// HATE_SPEECH refers to hateful content
+	HATE_SPEECH = REPORT_REASON("hate_speech")
+	// UNMARKED_SPOILER refers to spoilers without spoiler flag or markup
+	UNMARKED_SPOILER = REPORT_REASON("unmarked_spoiler")
+	// OFF_TOPIC refers to content unrelated to the movie
+	OFF_TOPIC = REPORT_REASON("off_topic")
+	// OTHER refers to any other reason explained in details
+	OTHER = REPORT_REASON("other")
+)
+
+// EXPORT_STATUS state of a personal data export
+type EXPORT_STATUS string
+
+const (
+	// EXPORT_PENDING refers to export waiting to be built
+	EXPORT_PENDING = EXPORT_STATUS("pending")
+	// EXPORT_RUNNING refers to export being built
+	EXPORT_RUNNING = EXPORT_STATUS("running")
+	// EXPORT_COMPLETED refers to export ready for download
+	EXPORT_COMPLETED = EXPORT_STATUS("completed")
+	// EXPORT_FAILED refers to export that could not be built
+	EXPORT_FAILED = EXPORT_STATUS("failed")
+	// EXPORT_EXPIRED refers to export whose archive is removed after its download period
+	EXPORT_EXPIRED = EXPORT_STATUS("expired")
+)
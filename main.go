@@ -9,6 +9,7 @@ import (
 	v1 "github.com/niloydeb1/Golang-Movie_API/src/v1"
 	"log"
 	"net/http"
+	"strings"
 	"time"
 )
 
@@ -16,10 +17,13 @@ func main() {
 	e := config.New()
 	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
 		AllowOrigins: []string{"*"},
-		AllowMethods: []string{http.MethodGet, http.MethodPut, http.MethodPost, http.MethodDelete},
+		AllowMethods: []string{http.MethodGet, http.MethodPut, http.MethodPatch, http.MethodPost, http.MethodDelete},
 	}))
 
 	initSuperAdmin()
+	initReviewIndexes()
+	initIndexes()
+	initDataExports()
 
 	api.Routes(e)
 	e.Logger.Fatal(e.Start(":" + config.ServerPort))
@@ -49,4 +53,85 @@ func initSuperAdmin() {
 	}
 }
 
+// initReviewIndexes enforces one review per user per movie. Existing duplicates are reported
+// and have to be resolved before the unique index can be created.
+func initReviewIndexes() {
+	duplicates := v1.Review{}.GetDuplicates()
+	if len(duplicates) > 0 {
+		log.Println("[WARNING] Found", len(duplicates), "reviewer and movie pairs with more than one review, unique review index is not created")
+		for _, duplicate := range duplicates {
+			log.Println("[WARNING] Duplicate reviews: reviewer_id=" + duplicate.ReviewerId + ", movie_id=" + duplicate.MovieId + ", review_ids=" + strings.Join(duplicate.ReviewIds, ","))
+		}
+		return
+	}
+	if err := (v1.Review{}).EnsureIndexes(); err != nil {
+		log.Println(err)
+	}
+}
+
+// initIndexes creates the indexes that enforce uniqueness of stored documents.
+func initIndexes() {
+	if err := (v1.User{}).EnsureIndexes(); err != nil {
+		log.Println(err)
+	}
+	if err := (v1.ReviewVote{}).EnsureIndexes(); err != nil {
+		log.Println(err)
+	}
+	if err := (v1.Report{}).EnsureIndexes(); err != nil {
+		log.Println(err)
+	}
+	if err := (v1.WatchlistEntry{}).EnsureIndexes(); err != nil {
+		log.Println(err)
+	}
+	if err := (v1.DiaryEntry{}).EnsureIndexes(); err != nil {
+		log.Println(err)
+	}
+	if err := (v1.MovieList{}).EnsureIndexes(); err != nil {
+		log.Println(err)
+	}
+	if err := (v1.Follow{}).EnsureIndexes(); err != nil {
+		log.Println(err)
+	}
+	if err := (v1.Activity{}).EnsureIndexes(); err != nil {
+		log.Println(err)
+	}
+	if err := (v1.UserRelation{}).EnsureIndexes(); err != nil {
+		log.Println(err)
+	}
+	if err := (v1.Reaction{}).EnsureIndexes(); err != nil {
+		log.Println(err)
+	}
+	if err := (v1.Notification{}).EnsureIndexes(); err != nil {
+		log.Println(err)
+	}
+	if err := (v1.DataExport{}).EnsureIndexes(); err != nil {
+		log.Println(err)
+	}
+}
+
+// initDataExports fails data exports that were interrupted by a restart, so they can be requested again,
+// and removes archives of expired exports now and periodically.
+func initDataExports() {
+	if err := (v1.DataExport{}).FailInterrupted(); err != nil {
+		log.Println(err)
+	}
+	removeExpiredDataExports()
+	if config.DataExportCleanupIntervalMinutes <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(time.Duration(config.DataExportCleanupIntervalMinutes) * time.Minute)
+		defer ticker.Stop()
+		for range ticker.C {
+			removeExpiredDataExports()
+		}
+	}()
+}
+
+func removeExpiredDataExports() {
+	if err := (v1.DataExport{}).RemoveExpired(time.Now().UTC(), v1.NewBlobStore()); err != nil {
+		log.Println("[ERROR] Failed to remove expired exports:", err.Error())
+	}
+}
+
 //swag init --parseDependency --parseInternal
\ No newline at end of file
@@ -0,0 +1,98 @@
+package v1
+
+import (
+	"bytes"
+	"errors"
+	"github.com/niloydeb1/Golang-Movie_API/config"
+	"image"
+	"image/color"
+	_ "image/gif"
+	_ "image/jpeg"
+	"image/png"
+	"strconv"
+)
+
+// ErrAvatarTooLarge is returned when an uploaded avatar exceeds the configured size limit.
+var ErrAvatarTooLarge = errors.New("avatar is larger than the allowed size")
+
+// AvatarBlobKey returns blob store key of the users avatar.
+func AvatarBlobKey(userId string) string {
+	return "avatars/" + userId + ".png"
+}
+
+// AvatarUrl returns the public url of the users avatar.
+func AvatarUrl(userId string) string {
+	return "/api/v1/users/" + userId + "/avatar"
+}
+
+// ProcessAvatar decodes a jpeg, png or gif image, crops it to a centered square and resizes it to
+// the configured avatar size. The avatar is returned png encoded.
+func ProcessAvatar(data []byte) ([]byte, error) {
+	if int64(len(data)) > config.AvatarMaxBytes {
+		return nil, ErrAvatarTooLarge
+	}
+	imageConfig, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.New("avatar must be a jpeg, png or gif image")
+	}
+	// refuse decompression bombs before decoding the pixels
+	if imageConfig.Width*imageConfig.Height > 40000000 {
+		return nil, errors.New("avatar dimensions are too large")
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.New("avatar must be a jpeg, png or gif image")
+	}
+	size := int(config.AvatarSize)
+	if size <= 0 {
+		return nil, errors.New("avatar size is configured as " + strconv.FormatInt(config.AvatarSize, 10))
+	}
+	avatar := resizeImage(src, cropSquare(src), size)
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, avatar); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// cropSquare returns the largest centered square of the image.
+func cropSquare(src image.Image) image.Rectangle {
+	bounds := src.Bounds()
+	side := bounds.Dx()
+	if bounds.Dy() < side {
+		side = bounds.Dy()
+	}
+	x := bounds.Min.X + (bounds.Dx()-side)/2
+	y := bounds.Min.Y + (bounds.Dy()-side)/2
+	return image.Rect(x, y, x+side, y+side)
+}
+
+// resizeImage scales the square area of the source image to a size by size image. Every target pixel
+// is the average of the source pixels it covers, or the nearest source pixel when scaling up.
+func resizeImage(src image.Image, area image.Rectangle, size int) *image.NRGBA {
+	dst := image.NewNRGBA(image.Rect(0, 0, size, size))
+	side := area.Dx()
+	for y := 0; y < size; y++ {
+		y0 := area.Min.Y + y*side/size
+		y1 := area.Min.Y + (y+1)*side/size
+		if y1 <= y0 {
+			y1 = y0 + 1
+		}
+		for x := 0; x < size; x++ {
+			x0 := area.Min.X + x*side/size
+			x1 := area.Min.X + (x+1)*side/size
+			if x1 <= x0 {
+				x1 = x0 + 1
+			}
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					pr, pg, pb, pa := src.At(sx, sy).RGBA()
+					r, g, b, a, n = r+uint64(pr), g+uint64(pg), b+uint64(pb), a+uint64(pa), n+1
+				}
+			}
+			dst.Set(x, y, color.RGBA64{R: uint16(r / n), G: uint16(g / n), B: uint16(b / n), A: uint16(a / n)})
+		}
+	}
+	return dst
+}
@@ -0,0 +1,93 @@
+package v1
+
+import (
+	"errors"
+	"github.com/niloydeb1/Golang-Movie_API/config"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrBlobNotFound is returned when a blob does not exist.
+var ErrBlobNotFound = errors.New("blob not found")
+
+// BlobStore stores binary objects such as user avatars by key.
+type BlobStore interface {
+	Put(key string, data []byte) error
+	Get(key string) ([]byte, error)
+	Delete(key string) error
+}
+
+// FileBlobStore stores blobs as files under a root directory.
+type FileBlobStore struct {
+	Root string
+}
+
+// NewBlobStore returns the blob store configured by environment.
+func NewBlobStore() BlobStore {
+	return FileBlobStore{Root: config.BlobStorePath}
+}
+
+func (f FileBlobStore) Put(key string, data []byte) error {
+	path, err := f.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		log.Println("[ERROR] Create blob directory:", err.Error())
+		return err
+	}
+	// write to a temporary file first, so that readers never see a partially written blob
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		log.Println("[ERROR] Write blob:", err.Error())
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		log.Println("[ERROR] Write blob:", err.Error())
+		return err
+	}
+	return nil
+}
+
+func (f FileBlobStore) Get(key string) ([]byte, error) {
+	path, err := f.path(key)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, ErrBlobNotFound
+	}
+	if err != nil {
+		log.Println("[ERROR] Read blob:", err.Error())
+		return nil, err
+	}
+	return data, nil
+}
+
+func (f FileBlobStore) Delete(key string) error {
+	path, err := f.path(key)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(path)
+	if os.IsNotExist(err) {
+		return ErrBlobNotFound
+	}
+	if err != nil {
+		log.Println("[ERROR] Delete blob:", err.Error())
+		return err
+	}
+	return nil
+}
+
+// path returns the file of the key. Keys must stay inside the root directory.
+func (f FileBlobStore) path(key string) (string, error) {
+	cleaned := filepath.Clean("/" + key)
+	if key == "" || strings.Contains(key, "..") || cleaned == "/" {
+		return "", errors.New("invalid blob key")
+	}
+	return filepath.Join(f.Root, filepath.FromSlash(cleaned)), nil
+}
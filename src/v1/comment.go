@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"github.com/niloydeb1/Golang-Movie_API/config"
+	"github.com/niloydeb1/Golang-Movie_API/enums"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"log"
@@ -12,19 +13,34 @@ import (
 
 const CommentCollection = "commentCollection"
 
+// DeletedCommentPlaceholder replaces the text of a deleted comment that has replies.
+const DeletedCommentPlaceholder = "[deleted]"
+
 type Comment struct {
-	ID             string    `json:"id" bson:"id"`
-	MovieId        string    `json:"movie_id" bson:"movie_id"`
-	ReviewId       string    `json:"review_id" bson:"review_id"`
-	CommenterId    string    `json:"commenter_id" bson:"commenter_id"`
-	CommenterEmail string    `json:"email" bson:"email"`
-	Comment        string    `json:"comment" bson:"comment"`
-	CreatedAt      time.Time `json:"created_at" bson:"created_at"`
+	ID              string           `json:"id" bson:"id"`
+	MovieId         string           `json:"movie_id" bson:"movie_id"`
+	ReviewId        string           `json:"review_id" bson:"review_id"`
+	ListId          string           `json:"list_id,omitempty" bson:"list_id,omitempty"`
+	ParentId        string           `json:"parent_id" bson:"parent_id"`
+	RootId          string           `json:"root_id" bson:"root_id"`
+	Depth           int64            `json:"depth" bson:"depth"`
+	Deleted         bool             `json:"deleted" bson:"deleted"`
+	CommenterId     string           `json:"commenter_id" bson:"commenter_id"`
+	CommenterEmail  string           `json:"email" bson:"email"`
+	Comment         string           `json:"comment" bson:"comment"`
+	RenderedComment string           `json:"rendered_comment,omitempty" bson:"rendered_comment,omitempty"`
+	Mentions        []Mention        `json:"mentions" bson:"mentions"`
+	Spoiler         bool             `json:"spoiler" bson:"spoiler"`
+	SpoilerMasked   bool             `json:"spoiler_masked,omitempty" bson:"-"`
+	CreatedAt       time.Time        `json:"created_at" bson:"created_at"`
+	EditedAt        *time.Time       `json:"edited_at,omitempty" bson:"edited_at,omitempty"`
+	Reactions       map[string]int64 `json:"reactions" bson:"reactions"`
+	Moderation      Moderation       `json:"moderation" bson:"moderation"`
 }
 
 func (c Comment) Validate() error {
-	if c.ReviewId == "" {
-		return errors.New("review id is not provided")
+	if c.ReviewId == "" && c.ParentId == "" && c.MovieId == "" && c.ListId == "" {
+		return errors.New("review id, list id or movie id is not provided")
 	}
 	if c.Comment == "" {
 		return errors.New("comment is not provided")
@@ -32,6 +48,27 @@ func (c Comment) Validate() error {
 	return nil
 }
 
+// IsEditableBy returns true if the user can edit the comment at the given time. The author can edit
+// within the configured edit window, admins can always edit.
+func (c Comment) IsEditableBy(userId string, role enums.ROLE, now time.Time) bool {
+	if c.Deleted {
+		return false
+	}
+	if role == enums.ADMIN || role == enums.SUPERADMIN {
+		return true
+	}
+	editWindow := time.Duration(config.CommentEditWindowMinutes) * time.Minute
+	return c.CommenterId == userId && now.Sub(c.CreatedAt) <= editWindow
+}
+
+// RenderMentions resolves mentions of the comment and renders them as links. Users who blocked the
+// commenter are not mentioned.
+func (c Comment) RenderMentions() Comment {
+	c.Mentions = WithoutBlockers(c.CommenterId, ResolveMentions(c.Comment))
+	c.RenderedComment = RenderMentions(c.Comment, c.Mentions)
+	return c
+}
+
 func (c Comment) GetByID(id string) Comment {
 	query := bson.M{
 		"$and": []bson.M{
@@ -48,13 +85,18 @@ func (c Comment) GetByID(id string) Comment {
 	return *res
 }
 
-func (c Comment) GetByReviewId(reviewId string, pagination Pagination) ([]Comment, int64) {
-	var data []Comment
-	query := bson.M{
+// DiscussionQuery restricts the query to discussion comments of a movie, that are not attached to a review.
+func DiscussionQuery(movieId string) bson.M {
+	return bson.M{
 		"$and": []bson.M{
-			{"review_id": reviewId},
+			{"movie_id": movieId},
+			{"review_id": bson.M{"$in": []interface{}{"", nil}}},
 		},
 	}
+}
+
+func (c Comment) Search(query bson.M, pagination Pagination, sort enums.COMMENT_SORT) ([]Comment, int64) {
+	var data []Comment
 	coll := config.GetDmManager().Db.Collection(CommentCollection)
 	skip := pagination.Page * pagination.Limit
 	findOptions := options.FindOptions{
@@ -62,6 +104,9 @@ func (c Comment) GetByReviewId(reviewId string, pagination Pagination) ([]Commen
 		Skip:  &skip,
 		Sort:  bson.M{"created_at": 1},
 	}
+	if sort == enums.NEWEST_FIRST {
+		findOptions.Sort = bson.M{"created_at": -1}
+	}
 	result, err := coll.Find(config.GetDmManager().Ctx, query, &findOptions)
 	if err != nil {
 		log.Println(err.Error())
@@ -92,6 +137,135 @@ func (c Comment) Store(comment Comment) error {
 	return nil
 }
 
+func (c Comment) Update(comment Comment) error {
+	filter := bson.M{"id": comment.ID}
+	update := bson.M{
+		"$set": bson.M{
+			"comment":          comment.Comment,
+			"rendered_comment": comment.RenderedComment,
+			"mentions":         comment.Mentions,
+			"spoiler":          comment.Spoiler,
+			"edited_at":        comment.EditedAt,
+			"moderation":       comment.Moderation,
+		},
+	}
+	coll := config.GetDmManager().Db.Collection(CommentCollection)
+	res, err := coll.UpdateOne(config.GetDmManager().Ctx, filter, update)
+	if err != nil {
+		log.Println("[ERROR] Update document:", err.Error())
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return errors.New("no comment found to update")
+	}
+	return nil
+}
+
+// UpdateCommenterEmail sets the email of comments of the user and of mentions of the user in comments.
+func (c Comment) UpdateCommenterEmail(userId, email string) error {
+	coll := config.GetDmManager().Db.Collection(CommentCollection)
+	_, err := coll.UpdateMany(config.GetDmManager().Ctx, bson.M{"commenter_id": userId}, bson.M{"$set": bson.M{"email": email}})
+	if err != nil {
+		log.Println("[ERROR] Update document:", err.Error())
+		return err
+	}
+	return updateMentionEmail(CommentCollection, userId, email)
+}
+
+// CountReplies returns number of direct replies of a comment.
+func (c Comment) CountReplies(id string) int64 {
+	coll := config.GetDmManager().Db.Collection(CommentCollection)
+	count, err := coll.CountDocuments(config.GetDmManager().Ctx, bson.M{"parent_id": id})
+	if err != nil {
+		log.Println(err.Error())
+	}
+	return count
+}
+
+// MarkDeleted replaces a comment with a placeholder, so that its replies keep their context.
+func (c Comment) MarkDeleted(id string) error {
+	filter := bson.M{"id": id}
+	update := bson.M{
+		"$set": bson.M{
+			"comment":          DeletedCommentPlaceholder,
+			"rendered_comment": "",
+			"mentions":         []Mention{},
+			"commenter_id":     "",
+			"email":            "",
+			"spoiler":          false,
+			"deleted":          true,
+		},
+	}
+	coll := config.GetDmManager().Db.Collection(CommentCollection)
+	res, err := coll.UpdateOne(config.GetDmManager().Ctx, filter, update)
+	if err != nil {
+		log.Println("[ERROR] Update document:", err.Error())
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return errors.New("no data found to delete")
+	}
+	return nil
+}
+
+// UpdateReactions sets the denormalized reaction counts of a comment.
+func (c Comment) UpdateReactions(id string, reactions map[string]int64) error {
+	filter := bson.M{"id": id}
+	update := bson.M{
+		"$set": bson.M{"reactions": reactions},
+	}
+	coll := config.GetDmManager().Db.Collection(CommentCollection)
+	res, err := coll.UpdateOne(config.GetDmManager().Ctx, filter, update)
+	if err != nil {
+		log.Println("[ERROR] Update document:", err.Error())
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return errors.New("no comment found to update")
+	}
+	return nil
+}
+
+func (c Comment) UpdateModeration(id string, moderation Moderation) error {
+	filter := bson.M{"id": id}
+	update := bson.M{
+		"$set": bson.M{"moderation": moderation},
+	}
+	coll := config.GetDmManager().Db.Collection(CommentCollection)
+	res, err := coll.UpdateOne(config.GetDmManager().Ctx, filter, update)
+	if err != nil {
+		log.Println("[ERROR] Update document:", err.Error())
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return errors.New("no comment found to moderate")
+	}
+	return nil
+}
+
+// DeleteByListId deletes every comment on the list and returns their ids.
+func (c Comment) DeleteByListId(listId string) ([]string, error) {
+	var comments []Comment
+	if err := findAll(CommentCollection, bson.M{"list_id": listId}, nil, &comments); err != nil {
+		log.Println(err.Error())
+		return nil, err
+	}
+	if len(comments) == 0 {
+		return nil, nil
+	}
+	coll := config.GetDmManager().Db.Collection(CommentCollection)
+	_, err := coll.DeleteMany(config.GetDmManager().Ctx, bson.M{"list_id": listId})
+	if err != nil {
+		log.Println("[ERROR]", err)
+		return nil, err
+	}
+	ids := make([]string, len(comments))
+	for i, comment := range comments {
+		ids[i] = comment.ID
+	}
+	return ids, nil
+}
+
 func (c Comment) Delete(id string) error {
 	coll := config.GetDmManager().Db.Collection(CommentCollection)
 	filter := bson.M{"id": id}
@@ -0,0 +1,85 @@
+package v1
+
+import (
+	"context"
+	"github.com/niloydeb1/Golang-Movie_API/config"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"log"
+	"time"
+)
+
+const CommentRevisionCollection = "commentRevisionCollection"
+
+// CommentRevision contains the text of a comment before an edit and what the edit changed.
+type CommentRevision struct {
+	ID        string       `json:"id" bson:"id"`
+	CommentId string       `json:"comment_id" bson:"comment_id"`
+	EditorId  string       `json:"editor_id" bson:"editor_id"`
+	Comment   string       `json:"comment" bson:"comment"`
+	Spoiler   bool         `json:"spoiler" bson:"spoiler"`
+	Changes   []TextChange `json:"changes" bson:"changes"`
+	CreatedAt time.Time    `json:"created_at" bson:"created_at"`
+}
+
+// NewCommentRevision returns the revision that turns the old comment into the edited one.
+func NewCommentRevision(id, editorId string, old, edited Comment) CommentRevision {
+	revision := CommentRevision{
+		ID:        id,
+		CommentId: old.ID,
+		EditorId:  editorId,
+		Comment:   old.Comment,
+		Spoiler:   old.Spoiler,
+		Changes:   []TextChange{},
+		CreatedAt: time.Now().UTC(),
+	}
+	if change := DiffText("comment", old.Comment, edited.Comment); change != nil {
+		revision.Changes = append(revision.Changes, *change)
+	}
+	return revision
+}
+
+func (r CommentRevision) Store(revision CommentRevision) error {
+	coll := config.GetDmManager().Db.Collection(CommentRevisionCollection)
+	_, err := coll.InsertOne(config.GetDmManager().Ctx, revision)
+	if err != nil {
+		log.Println("[ERROR] Insert document:", err.Error())
+		return err
+	}
+	return nil
+}
+
+func (r CommentRevision) GetByCommentId(commentId string, pagination Pagination) ([]CommentRevision, int64) {
+	var data []CommentRevision
+	query := bson.M{
+		"$and": []bson.M{
+			{"comment_id": commentId},
+		},
+	}
+	coll := config.GetDmManager().Db.Collection(CommentRevisionCollection)
+	skip := pagination.Page * pagination.Limit
+	findOptions := options.FindOptions{
+		Limit: &pagination.Limit,
+		Skip:  &skip,
+		Sort:  bson.M{"created_at": -1},
+	}
+	result, err := coll.Find(config.GetDmManager().Ctx, query, &findOptions)
+	if err != nil {
+		log.Println(err.Error())
+		return data, 0
+	}
+	for result.Next(context.TODO()) {
+		elemValue := new(CommentRevision)
+		err := result.Decode(elemValue)
+		if err != nil {
+			log.Println("[ERROR]", err)
+			break
+		}
+		data = append(data, *elemValue)
+	}
+	count, err := coll.CountDocuments(config.GetDmManager().Ctx, query)
+	if err != nil {
+		log.Println(err.Error())
+	}
+	return data, count
+}
@@ -0,0 +1,124 @@
+package v1
+
+import (
+	"context"
+	"github.com/niloydeb1/Golang-Movie_API/config"
+	"github.com/niloydeb1/Golang-Movie_API/enums"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"log"
+)
+
+// CommentNode contains a comment with its nested replies.
+type CommentNode struct {
+	Comment
+	Replies []CommentNode `json:"replies"`
+}
+
+// RootCommentQuery restricts the query to top level comments.
+func RootCommentQuery(query bson.M) bson.M {
+	root := bson.M{"parent_id": bson.M{"$in": []interface{}{"", nil}}}
+	if len(query) == 0 {
+		return root
+	}
+	return bson.M{"$and": []bson.M{query, root}}
+}
+
+// GetThreads returns a page of visible top level comments matching the query in the sort order, each
+// with its visible replies nested oldest first. Replies of comments that are not visible are left out.
+func (c Comment) GetThreads(query bson.M, pagination Pagination, sort enums.COMMENT_SORT) ([]CommentNode, int64) {
+	roots, total := c.Search(VisibleQuery(RootCommentQuery(query)), pagination, sort)
+	if len(roots) == 0 {
+		return []CommentNode{}, total
+	}
+	rootIds := make([]string, len(roots))
+	for i, root := range roots {
+		rootIds[i] = root.ID
+	}
+	replies := c.getReplies(rootIds)
+	children := make(map[string][]Comment)
+	for _, reply := range replies {
+		children[reply.ParentId] = append(children[reply.ParentId], reply)
+	}
+	threads := make([]CommentNode, len(roots))
+	for i, root := range roots {
+		threads[i] = buildCommentNode(root, children)
+	}
+	return threads, total
+}
+
+// FlattenCommentThreads returns the comments of the threads in depth first order.
+func FlattenCommentThreads(threads []CommentNode) []Comment {
+	var comments []Comment
+	for _, node := range threads {
+		comments = append(comments, node.Comment)
+		comments = append(comments, FlattenCommentThreads(node.Replies)...)
+	}
+	return comments
+}
+
+// WithoutCommenters returns the threads without comments of the commenters. Replies of a removed
+// comment are removed with it.
+func WithoutCommenters(threads []CommentNode, commenterIds []string) []CommentNode {
+	if len(commenterIds) == 0 {
+		return threads
+	}
+	excluded := make(map[string]bool, len(commenterIds))
+	for _, id := range commenterIds {
+		excluded[id] = true
+	}
+	return withoutCommenters(threads, excluded)
+}
+
+func withoutCommenters(threads []CommentNode, excluded map[string]bool) []CommentNode {
+	kept := make([]CommentNode, 0, len(threads))
+	for _, node := range threads {
+		if excluded[node.CommenterId] {
+			continue
+		}
+		node.Replies = withoutCommenters(node.Replies, excluded)
+		kept = append(kept, node)
+	}
+	return kept
+}
+
+// MaskSpoilers returns the thread with spoiler content of every comment redacted.
+func (n CommentNode) MaskSpoilers() CommentNode {
+	n.Comment = n.Comment.MaskSpoilers()
+	replies := make([]CommentNode, len(n.Replies))
+	for i, reply := range n.Replies {
+		replies[i] = reply.MaskSpoilers()
+	}
+	n.Replies = replies
+	return n
+}
+
+func buildCommentNode(comment Comment, children map[string][]Comment) CommentNode {
+	node := CommentNode{Comment: comment, Replies: []CommentNode{}}
+	for _, child := range children[comment.ID] {
+		node.Replies = append(node.Replies, buildCommentNode(child, children))
+	}
+	return node
+}
+
+// getReplies returns visible replies of all threads started by the root comments, oldest first.
+func (c Comment) getReplies(rootIds []string) []Comment {
+	var data []Comment
+	query := VisibleQuery(bson.M{"root_id": bson.M{"$in": rootIds}})
+	coll := config.GetDmManager().Db.Collection(CommentCollection)
+	result, err := coll.Find(config.GetDmManager().Ctx, query, &options.FindOptions{Sort: bson.M{"created_at": 1}})
+	if err != nil {
+		log.Println(err.Error())
+		return data
+	}
+	for result.Next(context.TODO()) {
+		elemValue := new(Comment)
+		err := result.Decode(elemValue)
+		if err != nil {
+			log.Println("[ERROR]", err)
+			break
+		}
+		data = append(data, *elemValue)
+	}
+	return data
+}
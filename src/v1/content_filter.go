@@ -0,0 +1,318 @@
+package v1
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/niloydeb1/Golang-Movie_API/config"
+	"github.com/niloydeb1/Golang-Movie_API/enums"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+const FilterDecisionCollection = "filterDecisionCollection"
+
+// linkPattern matches links in user generated text.
+var linkPattern = regexp.MustCompile(`(?i)\b(?:https?://|www\.)\S+`)
+
+// ContentFilter checks text of reviews and comments on submission and edit.
+type ContentFilter interface {
+	// Name returns name of the filter, recorded with its decisions.
+	Name() string
+	// Apply returns the verdict of the filter, or nil if the content passes.
+	Apply(content FilterContent) *FilterVerdict
+}
+
+// FilterContent contains a single text field of a review or comment under filtering.
+type FilterContent struct {
+	ContentType enums.CONTENT_TYPE
+	TargetId    string
+	AuthorId    string
+	Field       string
+	Text        string
+}
+
+// FilterVerdict contains the action a filter takes on content.
+type FilterVerdict struct {
+	Action enums.FILTER_ACTION
+	Reason string
+	// Text is the masked text if Action is MASK.
+	Text string
+}
+
+// FilterField refers to a text field that is filtered. Masked text is written back to it.
+type FilterField struct {
+	Name string
+	Text *string
+}
+
+// FilterDecision contains a decision of a content filter, recorded for admins.
+type FilterDecision struct {
+	ID          string              `json:"id" bson:"id"`
+	ContentType enums.CONTENT_TYPE  `json:"content_type" bson:"content_type"`
+	TargetId    string              `json:"target_id" bson:"target_id"`
+	AuthorId    string              `json:"author_id" bson:"author_id"`
+	Field       string              `json:"field" bson:"field"`
+	Filter      string              `json:"filter" bson:"filter"`
+	Action      enums.FILTER_ACTION `json:"action" bson:"action"`
+	Reason      string              `json:"reason" bson:"reason"`
+	CreatedAt   time.Time           `json:"created_at" bson:"created_at"`
+}
+
+// ContentFilterResult contains the outcome of running a content filter pipeline.
+type ContentFilterResult struct {
+	Rejected  bool
+	Flagged   bool
+	Decisions []FilterDecision
+}
+
+// ContentFilterPipeline runs content filters one after another. Text masked by a filter is
+// passed on to the next one.
+type ContentFilterPipeline struct {
+	Filters []ContentFilter
+}
+
+// WordListFilter acts on text containing any of the configured words. It is built by
+// NewWordListFilter, which compiles the words once.
+type WordListFilter struct {
+	Words   []string
+	Action  enums.FILTER_ACTION
+	pattern *regexp.Regexp
+}
+
+// SpamFilter acts on text with too many links or text the author recently posted already.
+type SpamFilter struct {
+	MaxLinks    int64
+	Action      enums.FILTER_ACTION
+	IsDuplicate func(content FilterContent) bool
+}
+
+// MaxLengthFilter rejects text longer than the maximum length.
+type MaxLengthFilter struct {
+	MaxLength int64
+}
+
+var (
+	contentFilterPipeline     ContentFilterPipeline
+	contentFilterPipelineOnce sync.Once
+)
+
+// NewContentFilterPipeline returns the content filter pipeline configured by environment. It is
+// built on first use and shared afterwards.
+func NewContentFilterPipeline() ContentFilterPipeline {
+	contentFilterPipelineOnce.Do(func() {
+		contentFilterPipeline = ContentFilterPipeline{
+			Filters: []ContentFilter{
+				MaxLengthFilter{MaxLength: config.ContentFilterMaxLength},
+				NewWordListFilter(config.ContentFilterWords, config.ContentFilterWordAction),
+				SpamFilter{MaxLinks: config.ContentFilterMaxLinks, Action: config.ContentFilterSpamAction, IsDuplicate: isRecentDuplicate},
+			},
+		}
+	})
+	return contentFilterPipeline
+}
+
+// NewWordListFilter returns a filter that takes the action on text containing any of the words.
+func NewWordListFilter(words []string, action enums.FILTER_ACTION) WordListFilter {
+	filter := WordListFilter{Words: words, Action: action}
+	if len(words) == 0 {
+		return filter
+	}
+	quoted := make([]string, len(words))
+	for i, word := range words {
+		quoted[i] = regexp.QuoteMeta(word)
+	}
+	filter.pattern = regexp.MustCompile(`(?i)\b(?:` + strings.Join(quoted, "|") + `)\b`)
+	return filter
+}
+
+// Run filters the fields and returns the decisions of the filters. Decisions are not stored.
+func (p ContentFilterPipeline) Run(contentType enums.CONTENT_TYPE, targetId, authorId string, fields ...FilterField) ContentFilterResult {
+	result := ContentFilterResult{}
+	for _, field := range fields {
+		for _, filter := range p.Filters {
+			verdict := filter.Apply(FilterContent{
+				ContentType: contentType,
+				TargetId:    targetId,
+				AuthorId:    authorId,
+				Field:       field.Name,
+				Text:        *field.Text,
+			})
+			if verdict == nil {
+				continue
+			}
+			switch verdict.Action {
+			case enums.REJECT:
+				result.Rejected = true
+			case enums.FLAG:
+				result.Flagged = true
+			case enums.MASK:
+				*field.Text = verdict.Text
+			}
+			result.Decisions = append(result.Decisions, FilterDecision{
+				ContentType: contentType,
+				TargetId:    targetId,
+				AuthorId:    authorId,
+				Field:       field.Name,
+				Filter:      filter.Name(),
+				Action:      verdict.Action,
+				Reason:      verdict.Reason,
+				CreatedAt:   time.Now().UTC(),
+			})
+		}
+	}
+	return result
+}
+
+// Reason returns the reasons of the decisions that took the action.
+func (r ContentFilterResult) Reason(action enums.FILTER_ACTION) string {
+	var reasons []string
+	for _, decision := range r.Decisions {
+		if decision.Action == action {
+			reasons = append(reasons, decision.Field+": "+decision.Reason)
+		}
+	}
+	return strings.Join(reasons, "; ")
+}
+
+func (f WordListFilter) Name() string {
+	return "word_list"
+}
+
+func (f WordListFilter) Apply(content FilterContent) *FilterVerdict {
+	if f.pattern == nil || !f.pattern.MatchString(content.Text) {
+		return nil
+	}
+	return &FilterVerdict{
+		Action: f.Action,
+		Reason: "contains blocked words",
+		Text: f.pattern.ReplaceAllStringFunc(content.Text, func(word string) string {
+			return strings.Repeat("*", utf8.RuneCountInString(word))
+		}),
+	}
+}
+
+func (f SpamFilter) Name() string {
+	return "spam"
+}
+
+func (f SpamFilter) Apply(content FilterContent) *FilterVerdict {
+	if links := int64(len(linkPattern.FindAllString(content.Text, -1))); links > f.MaxLinks {
+		return &FilterVerdict{
+			Action: f.Action,
+			Reason: "contains " + strconv.FormatInt(links, 10) + " links, at most " + strconv.FormatInt(f.MaxLinks, 10) + " are allowed",
+			Text:   linkPattern.ReplaceAllString(content.Text, "[link removed]"),
+		}
+	}
+	if f.IsDuplicate != nil && f.IsDuplicate(content) {
+		action := f.Action
+		if action == enums.MASK {
+			// duplicate text can not be masked, it goes to moderation instead
+			action = enums.FLAG
+		}
+		return &FilterVerdict{
+			Action: action,
+			Reason: "duplicates recently posted text",
+			Text:   content.Text,
+		}
+	}
+	return nil
+}
+
+func (f MaxLengthFilter) Name() string {
+	return "max_length"
+}
+
+func (f MaxLengthFilter) Apply(content FilterContent) *FilterVerdict {
+	if f.MaxLength <= 0 || int64(utf8.RuneCountInString(content.Text)) <= f.MaxLength {
+		return nil
+	}
+	return &FilterVerdict{
+		Action: enums.REJECT,
+		Reason: "is longer than " + strconv.FormatInt(f.MaxLength, 10) + " characters",
+		Text:   content.Text,
+	}
+}
+
+// isRecentDuplicate returns true if the author posted the same review description or comment
+// within the configured duplicate window. Review titles are not checked.
+func isRecentDuplicate(content FilterContent) bool {
+	since := time.Now().UTC().Add(-time.Duration(config.ContentFilterDuplicateWindowHours) * time.Hour)
+	var collection string
+	var query bson.M
+	switch {
+	case content.ContentType == enums.REVIEW && content.Field == "description":
+		collection = ReviewCollection
+		query = bson.M{"reviewer_id": content.AuthorId, "description": content.Text}
+	case content.ContentType == enums.COMMENT && content.Field == "comment":
+		collection = CommentCollection
+		query = bson.M{"commenter_id": content.AuthorId, "comment": content.Text}
+	default:
+		return false
+	}
+	query["created_at"] = bson.M{"$gte": since}
+	query["id"] = bson.M{"$ne": content.TargetId}
+	coll := config.GetDmManager().Db.Collection(collection)
+	count, err := coll.CountDocuments(config.GetDmManager().Ctx, query)
+	if err != nil {
+		log.Println(err.Error())
+		return false
+	}
+	return count > 0
+}
+
+func (f FilterDecision) StoreMany(decisions []FilterDecision) error {
+	if len(decisions) == 0 {
+		return nil
+	}
+	documents := make([]interface{}, len(decisions))
+	for i, decision := range decisions {
+		if decision.ID == "" {
+			decision.ID = uuid.New().String()
+		}
+		documents[i] = decision
+	}
+	coll := config.GetDmManager().Db.Collection(FilterDecisionCollection)
+	_, err := coll.InsertMany(config.GetDmManager().Ctx, documents)
+	if err != nil {
+		log.Println("[ERROR] Insert document:", err.Error())
+		return err
+	}
+	return nil
+}
+
+func (f FilterDecision) Search(query bson.M, pagination Pagination) ([]FilterDecision, int64) {
+	var data []FilterDecision
+	coll := config.GetDmManager().Db.Collection(FilterDecisionCollection)
+	skip := pagination.Page * pagination.Limit
+	findOptions := options.FindOptions{
+		Limit: &pagination.Limit,
+		Skip:  &skip,
+		Sort:  bson.M{"created_at": -1},
+	}
+	result, err := coll.Find(config.GetDmManager().Ctx, query, &findOptions)
+	if err != nil {
+		log.Println(err.Error())
+		return data, 0
+	}
+	for result.Next(context.TODO()) {
+		elemValue := new(FilterDecision)
+		err := result.Decode(elemValue)
+		if err != nil {
+			log.Println("[ERROR]", err)
+			break
+		}
+		data = append(data, *elemValue)
+	}
+	count, err := coll.CountDocuments(config.GetDmManager().Ctx, query)
+	if err != nil {
+		log.Println(err.Error())
+	}
+	return data, count
+}
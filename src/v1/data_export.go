@@ -0,0 +1,468 @@
+package v1
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"github.com/niloydeb1/Golang-Movie_API/config"
+	"github.com/niloydeb1/Golang-Movie_API/enums"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"io"
+	"log"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const DataExportCollection = "dataExportCollection"
+
+var (
+	// ErrDataExportInProgress is returned when an export of the user is already pending or running.
+	ErrDataExportInProgress = errors.New("an export of the user is already in progress")
+	// ErrDataExportNotDownloadable is returned for exports that are not built or expired.
+	ErrDataExportNotDownloadable = errors.New("export is not available for download")
+)
+
+// DataExport is an asynchronous export of the personal data of a user into a zip archive of json and
+// csv files. The archive is kept in the blob store until the export expires.
+type DataExport struct {
+	ID          string              `json:"id" bson:"id"`
+	UserId      string              `json:"user_id" bson:"user_id"`
+	RequestedBy string              `json:"requested_by" bson:"requested_by"`
+	Status      enums.EXPORT_STATUS `json:"status" bson:"status"`
+	InProgress  bool                `json:"-" bson:"in_progress,omitempty"`
+	Error       string              `json:"error,omitempty" bson:"error,omitempty"`
+	Size        int64               `json:"size,omitempty" bson:"size,omitempty"`
+	CreatedAt   time.Time           `json:"created_at" bson:"created_at"`
+	CompletedAt *time.Time          `json:"completed_at,omitempty" bson:"completed_at,omitempty"`
+	ExpiresAt   *time.Time          `json:"expires_at,omitempty" bson:"expires_at,omitempty"`
+	DownloadUrl string              `json:"download_url,omitempty" bson:"-"`
+}
+
+// TokenMetadata describes a token issued to a user without its secret value.
+type TokenMetadata struct {
+	Type      string    `json:"type"`
+	IssuedAt  time.Time `json:"issued_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// DataExportBlobKey returns blob store key of the archive of the export.
+func DataExportBlobKey(id string) string {
+	return "exports/" + id + ".zip"
+}
+
+// DataExportFileName returns file name the archive of the export is downloaded as.
+func DataExportFileName(export DataExport) string {
+	return "export-" + export.CreatedAt.Format("2006-01-02") + "-" + export.ID + ".zip"
+}
+
+// IsDownloadable returns true if the archive of the export is built and not expired.
+func (d DataExport) IsDownloadable(now time.Time) bool {
+	return d.Status == enums.EXPORT_COMPLETED && d.ExpiresAt != nil && now.Before(*d.ExpiresAt)
+}
+
+// WithDownloadUrl returns the export with a signed download link if it is downloadable. The link
+// expires with the export.
+func (d DataExport) WithDownloadUrl() (DataExport, error) {
+	now := time.Now().UTC()
+	if !d.IsDownloadable(now) {
+		return d, nil
+	}
+	token, err := Jwt{}.GenerateScopedToken(d.UserId, enums.DATA_EXPORT, d.ExpiresAt.Sub(now), map[string]string{"export_id": d.ID})
+	if err != nil {
+		return d, err
+	}
+	d.DownloadUrl = config.ApiBaseUrl + "/api/v1/exports/download?token=" + url.QueryEscape(token)
+	return d, nil
+}
+
+// GetDownloadableDataExport returns the export of a valid download token. A token is rejected once
+// the export expired or the user is no longer active.
+func GetDownloadableDataExport(token string) (DataExport, error) {
+	userId, data, err := Jwt{}.ParseScopedToken(token, enums.DATA_EXPORT)
+	if err != nil {
+		return DataExport{}, err
+	}
+	export := DataExport{}.GetByID(data["export_id"])
+	if export.ID == "" || export.UserId != userId || !export.IsDownloadable(time.Now().UTC()) {
+		return DataExport{}, ErrDataExportNotDownloadable
+	}
+	if (User{}).GetByID(userId).Status != enums.ACTIVE {
+		return DataExport{}, ErrDataExportNotDownloadable
+	}
+	return export, nil
+}
+
+// Store stores the export. It returns ErrDataExportInProgress if another export of the user is
+// pending or running, which the unique in progress index enforces.
+func (d DataExport) Store(export DataExport) error {
+	export.InProgress = isExportInProgress(export.Status)
+	coll := config.GetDmManager().Db.Collection(DataExportCollection)
+	_, err := coll.InsertOne(config.GetDmManager().Ctx, export)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrDataExportInProgress
+	}
+	if err != nil {
+		log.Println("[ERROR] Insert document:", err.Error())
+		return err
+	}
+	return nil
+}
+
+func (d DataExport) GetByID(id string) DataExport {
+	coll := config.GetDmManager().Db.Collection(DataExportCollection)
+	result := coll.FindOne(config.GetDmManager().Ctx, bson.M{"id": id})
+	res := new(DataExport)
+	err := result.Decode(res)
+	if err != nil && err != mongo.ErrNoDocuments {
+		log.Println("[ERROR]", err)
+	}
+	return *res
+}
+
+// GetByUserId returns a page of exports of the user, latest first.
+func (d DataExport) GetByUserId(userId string, pagination Pagination) ([]DataExport, int64) {
+	data := []DataExport{}
+	query := bson.M{"user_id": userId}
+	coll := config.GetDmManager().Db.Collection(DataExportCollection)
+	skip := pagination.Page * pagination.Limit
+	findOptions := options.FindOptions{
+		Limit: &pagination.Limit,
+		Skip:  &skip,
+		Sort:  bson.D{{Key: "created_at", Value: -1}, {Key: "id", Value: -1}},
+	}
+	result, err := coll.Find(config.GetDmManager().Ctx, query, &findOptions)
+	if err != nil {
+		log.Println(err.Error())
+		return data, 0
+	}
+	for result.Next(context.TODO()) {
+		elemValue := new(DataExport)
+		err := result.Decode(elemValue)
+		if err != nil {
+			log.Println("[ERROR]", err)
+			break
+		}
+		data = append(data, *elemValue)
+	}
+	count, err := coll.CountDocuments(config.GetDmManager().Ctx, query)
+	if err != nil {
+		log.Println(err.Error())
+	}
+	return data, count
+}
+
+// UpdateStatus sets status of the export with the reason it failed, if any.
+func (d DataExport) UpdateStatus(id string, status enums.EXPORT_STATUS, reason string) error {
+	return d.update(id, bson.M{"status": status, "error": reason})
+}
+
+// Complete marks the export built with the size of its archive, downloadable until it expires.
+func (d DataExport) Complete(id string, size int64, completedAt, expiresAt time.Time) error {
+	return d.update(id, bson.M{"status": enums.EXPORT_COMPLETED, "size": size, "completed_at": completedAt, "expires_at": expiresAt})
+}
+
+func (d DataExport) update(id string, set bson.M) error {
+	update := bson.M{"$set": set}
+	if status, ok := set["status"].(enums.EXPORT_STATUS); ok && !isExportInProgress(status) {
+		update["$unset"] = bson.M{"in_progress": ""}
+	}
+	coll := config.GetDmManager().Db.Collection(DataExportCollection)
+	_, err := coll.UpdateOne(config.GetDmManager().Ctx, bson.M{"id": id}, update)
+	if err != nil {
+		log.Println("[ERROR]", err)
+		return err
+	}
+	return nil
+}
+
+// RemoveExpired deletes archives of exports that expired before now and marks the exports expired.
+func (d DataExport) RemoveExpired(now time.Time, store BlobStore) error {
+	var expired []DataExport
+	err := findAll(DataExportCollection, bson.M{"status": enums.EXPORT_COMPLETED, "expires_at": bson.M{"$lte": now}}, nil, &expired)
+	if err != nil {
+		log.Println(err.Error())
+		return err
+	}
+	for _, export := range expired {
+		if err := store.Delete(DataExportBlobKey(export.ID)); err != nil && err != ErrBlobNotFound {
+			log.Println("[ERROR] Failed to delete export archive:", err.Error())
+			continue
+		}
+		if err := d.UpdateStatus(export.ID, enums.EXPORT_EXPIRED, ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteByUserId deletes every export of the user with its archive.
+func (d DataExport) DeleteByUserId(userId string, store BlobStore) error {
+	var exports []DataExport
+	err := findAll(DataExportCollection, bson.M{"user_id": userId}, nil, &exports)
+	if err != nil {
+		log.Println(err.Error())
+		return err
+	}
+	for _, export := range exports {
+		if err := store.Delete(DataExportBlobKey(export.ID)); err != nil && err != ErrBlobNotFound {
+			log.Println("[ERROR] Failed to delete export archive:", err.Error())
+			return err
+		}
+	}
+	coll := config.GetDmManager().Db.Collection(DataExportCollection)
+	_, err = coll.DeleteMany(config.GetDmManager().Ctx, bson.M{"user_id": userId})
+	if err != nil {
+		log.Println("[ERROR]", err)
+		return err
+	}
+	return nil
+}
+
+// FailInterrupted marks exports that were pending or running when the server stopped as failed,
+// so users can request them again.
+func (d DataExport) FailInterrupted() error {
+	coll := config.GetDmManager().Db.Collection(DataExportCollection)
+	_, err := coll.UpdateMany(config.GetDmManager().Ctx,
+		bson.M{"status": bson.M{"$in": []enums.EXPORT_STATUS{enums.EXPORT_PENDING, enums.EXPORT_RUNNING}}},
+		bson.M{"$set": bson.M{"status": enums.EXPORT_FAILED, "error": "export was interrupted, please request it again"}, "$unset": bson.M{"in_progress": ""}})
+	if err != nil {
+		log.Println("[ERROR]", err)
+		return err
+	}
+	return nil
+}
+
+func (d DataExport) EnsureIndexes() error {
+	coll := config.GetDmManager().Db.Collection(DataExportCollection)
+	_, err := coll.Indexes().CreateMany(config.GetDmManager().Ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "id", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "expires_at", Value: 1}}},
+		{
+			Keys:    bson.D{{Key: "user_id", Value: 1}},
+			Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"in_progress": true}),
+		},
+	})
+	return err
+}
+
+// isExportInProgress returns true if the export of the status is pending or running.
+func isExportInProgress(status enums.EXPORT_STATUS) bool {
+	return status == enums.EXPORT_PENDING || status == enums.EXPORT_RUNNING
+}
+
+// BuildDataExportArchive returns a zip archive of the personal data of the user: profile, reviews,
+// comments, token metadata, watchlist and lists. Every collection is written as json, and as csv
+// where it is tabular.
+func BuildDataExportArchive(user User) ([]byte, error) {
+	reviews := []Review{}
+	comments := []Comment{}
+	watchlist := []WatchlistEntry{}
+	lists := []MovieList{}
+	for _, source := range []struct {
+		collection string
+		query      bson.M
+		sort       bson.M
+		results    interface{}
+	}{
+		{ReviewCollection, bson.M{"reviewer_id": user.ID}, bson.M{"created_at": 1}, &reviews},
+		{CommentCollection, bson.M{"commenter_id": user.ID}, bson.M{"created_at": 1}, &comments},
+		{WatchlistCollection, bson.M{"user_id": user.ID}, bson.M{"added_at": 1}, &watchlist},
+		{MovieListCollection, bson.M{"owner_id": user.ID}, bson.M{"created_at": 1}, &lists},
+	} {
+		if err := findAll(source.collection, source.query, source.sort, source.results); err != nil {
+			return nil, err
+		}
+	}
+	for i := range lists {
+		lists[i] = lists[i].WithRanks()
+	}
+
+	buffer := new(bytes.Buffer)
+	archive := zip.NewWriter(buffer)
+	files := []struct {
+		name  string
+		write func(io.Writer) error
+	}{
+		{"profile.json", jsonFile(NewUserSelfView(user))},
+		{"reviews.json", jsonFile(reviews)},
+		{"reviews.csv", csvFile(reviewCsvRows(reviews))},
+		{"comments.json", jsonFile(comments)},
+		{"comments.csv", csvFile(commentCsvRows(comments))},
+		{"tokens.json", jsonFile(tokenMetadata(user.ID))},
+		{"watchlist.json", jsonFile(watchlist)},
+		{"watchlist.csv", csvFile(watchlistCsvRows(watchlist))},
+		{"lists.json", jsonFile(lists)},
+		{"lists.csv", csvFile(listCsvRows(lists))},
+	}
+	for _, file := range files {
+		writer, err := archive.Create(file.name)
+		if err != nil {
+			return nil, err
+		}
+		if err := file.write(writer); err != nil {
+			return nil, err
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// CsvSafe prefixes user provided values that spreadsheet applications would run as formulas.
+func CsvSafe(value string) string {
+	if value != "" && strings.ContainsAny(value[:1], "=+-@\t\r") {
+		return "'" + value
+	}
+	return value
+}
+
+// CsvSafePhone returns the phone number as is if it is in E.164 format, whose leading + is not a
+// formula, and guarded by CsvSafe otherwise.
+func CsvSafePhone(value string) string {
+	if e164Pattern.MatchString(value) {
+		return value
+	}
+	return CsvSafe(value)
+}
+
+// findAll decodes every document of the collection matching the query into results, a pointer to a slice.
+func findAll(collection string, query bson.M, sort bson.M, results interface{}) error {
+	coll := config.GetDmManager().Db.Collection(collection)
+	findOptions := options.FindOptions{}
+	if sort != nil {
+		findOptions.Sort = sort
+	}
+	result, err := coll.Find(config.GetDmManager().Ctx, query, &findOptions)
+	if err != nil {
+		return err
+	}
+	return result.All(config.GetDmManager().Ctx, results)
+}
+
+// tokenMetadata returns issue and expiry times of the tokens of every session of the user.
+func tokenMetadata(userId string) []TokenMetadata {
+	data := []TokenMetadata{}
+	for _, token := range (TokenService{}).GetAllByUID(userId) {
+		for _, issued := range []struct {
+			kind  string
+			value string
+		}{{"access", token.Token}, {"refresh", token.RefreshToken}} {
+			if issued.value == "" {
+				continue
+			}
+			issuedAt, expiresAt := Jwt{}.GetTokenTimes(issued.value)
+			data = append(data, TokenMetadata{Type: issued.kind, IssuedAt: issuedAt, ExpiresAt: expiresAt})
+		}
+	}
+	return data
+}
+
+func jsonFile(data interface{}) func(io.Writer) error {
+	return func(w io.Writer) error {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(data)
+	}
+}
+
+func csvFile(rows [][]string) func(io.Writer) error {
+	return func(w io.Writer) error {
+		writer := csv.NewWriter(w)
+		if err := writer.WriteAll(rows); err != nil {
+			return err
+		}
+		return writer.Error()
+	}
+}
+
+func reviewCsvRows(reviews []Review) [][]string {
+	rows := [][]string{{"id", "movie_id", "movie_title", "review_title", "description", "spoiler", "created_at", "edited_at", "moderation_state"}}
+	for _, review := range reviews {
+		rows = append(rows, []string{
+			review.ID,
+			review.Movie.ID,
+			CsvSafe(review.Movie.Title),
+			CsvSafe(review.ReviewTitle),
+			CsvSafe(review.Description),
+			strconv.FormatBool(review.Spoiler),
+			review.CreatedAt.Format(time.RFC3339),
+			csvTime(review.EditedAt),
+			string(review.Moderation.State),
+		})
+	}
+	return rows
+}
+
+func commentCsvRows(comments []Comment) [][]string {
+	rows := [][]string{{"id", "movie_id", "review_id", "list_id", "parent_id", "comment", "deleted", "spoiler", "created_at", "edited_at", "moderation_state"}}
+	for _, comment := range comments {
+		rows = append(rows, []string{
+			comment.ID,
+			comment.MovieId,
+			comment.ReviewId,
+			comment.ListId,
+			comment.ParentId,
+			CsvSafe(comment.Comment),
+			strconv.FormatBool(comment.Deleted),
+			strconv.FormatBool(comment.Spoiler),
+			comment.CreatedAt.Format(time.RFC3339),
+			csvTime(comment.EditedAt),
+			string(comment.Moderation.State),
+		})
+	}
+	return rows
+}
+
+func watchlistCsvRows(watchlist []WatchlistEntry) [][]string {
+	rows := [][]string{{"movie_id", "title", "year", "priority", "notes", "added_at"}}
+	for _, entry := range watchlist {
+		rows = append(rows, []string{
+			entry.Movie.ID,
+			CsvSafe(entry.Movie.Title),
+			strconv.FormatInt(entry.Movie.Year, 10),
+			string(entry.Priority),
+			CsvSafe(entry.Notes),
+			entry.AddedAt.Format(time.RFC3339),
+		})
+	}
+	return rows
+}
+
+// listCsvRows returns a row for every movie on the lists, lists without movies get a row without movie.
+func listCsvRows(lists []MovieList) [][]string {
+	rows := [][]string{{"list_id", "list_name", "visibility", "position", "movie_id", "title", "notes", "added_at"}}
+	for _, list := range lists {
+		if len(list.Entries) == 0 {
+			rows = append(rows, []string{list.ID, CsvSafe(list.Name), string(list.Visibility), "", "", "", "", ""})
+		}
+		for i, entry := range list.Entries {
+			rows = append(rows, []string{
+				list.ID,
+				CsvSafe(list.Name),
+				string(list.Visibility),
+				strconv.Itoa(i + 1),
+				entry.Movie.ID,
+				CsvSafe(entry.Movie.Title),
+				CsvSafe(entry.Notes),
+				entry.AddedAt.Format(time.RFC3339),
+			})
+		}
+	}
+	return rows
+}
+
+func csvTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,279 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"github.com/niloydeb1/Golang-Movie_API/config"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"log"
+	"strconv"
+	"time"
+)
+
+const DiaryCollection = "diaryCollection"
+
+// DiaryDateLayout is the layout of watched on dates of diary entries.
+const DiaryDateLayout = "2006-01-02"
+
+const (
+	// MinDiaryRating is the lowest rating a diary entry can give to a movie.
+	MinDiaryRating = 1
+	// MaxDiaryRating is the highest rating a diary entry can give to a movie.
+	MaxDiaryRating = 10
+)
+
+// DiaryEntry is a viewing of a movie logged by a user.
+type DiaryEntry struct {
+	ID        string       `json:"id" bson:"id"`
+	UserId    string       `json:"user_id" bson:"user_id"`
+	Movie     MovieSummary `json:"movie" bson:"movie"`
+	WatchedOn string       `json:"watched_on" bson:"watched_on"`
+	Rewatch   bool         `json:"rewatch" bson:"rewatch"`
+	Rating    *int64       `json:"rating,omitempty" bson:"rating,omitempty"`
+	ReviewId  string       `json:"review_id,omitempty" bson:"review_id,omitempty"`
+	CreatedAt time.Time    `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time    `json:"updated_at" bson:"updated_at"`
+}
+
+// DiaryEntryDto contains data for logging a viewing, the movie is given either by id or title.
+// Rewatch is derived from earlier entries of the movie if it is not provided.
+type DiaryEntryDto struct {
+	MovieId   string `json:"movie_id" bson:"movie_id"`
+	Title     string `json:"title" bson:"title"`
+	WatchedOn string `json:"watched_on" bson:"watched_on"`
+	Rewatch   *bool  `json:"rewatch" bson:"rewatch"`
+	Rating    *int64 `json:"rating" bson:"rating"`
+	ReviewId  string `json:"review_id" bson:"review_id"`
+}
+
+// DiaryUpdateDto contains data for updating a diary entry, nil fields are left unchanged. An
+// empty review id unlinks the review.
+type DiaryUpdateDto struct {
+	WatchedOn *string `json:"watched_on" bson:"watched_on"`
+	Rewatch   *bool   `json:"rewatch" bson:"rewatch"`
+	Rating    *int64  `json:"rating" bson:"rating"`
+	ReviewId  *string `json:"review_id" bson:"review_id"`
+}
+
+// CalendarDay contains diary entries of a day.
+type CalendarDay struct {
+	Date    string       `json:"date"`
+	Entries []DiaryEntry `json:"entries"`
+}
+
+// Validate validates DiaryEntryDto data
+func (d DiaryEntryDto) Validate(now time.Time) error {
+	if d.MovieId == "" && d.Title == "" {
+		return errors.New("movie id or title is required")
+	}
+	if err := ValidateWatchedOn(d.WatchedOn, now); err != nil {
+		return err
+	}
+	return validateDiaryRating(d.Rating)
+}
+
+// Validate validates DiaryUpdateDto data
+func (d DiaryUpdateDto) Validate(now time.Time) error {
+	if d.WatchedOn != nil {
+		if err := ValidateWatchedOn(*d.WatchedOn, now); err != nil {
+			return err
+		}
+	}
+	return validateDiaryRating(d.Rating)
+}
+
+// ValidateWatchedOn returns an error if the date is not a YYYY-MM-DD date or is in the future.
+// A day of slack is given for users ahead of UTC.
+func ValidateWatchedOn(date string, now time.Time) error {
+	watchedOn, err := time.Parse(DiaryDateLayout, date)
+	if err != nil {
+		return errors.New("watched on must be a YYYY-MM-DD date")
+	}
+	if watchedOn.After(now.UTC().AddDate(0, 0, 1)) {
+		return errors.New("watched on must not be in the future")
+	}
+	return nil
+}
+
+func validateDiaryRating(rating *int64) error {
+	if rating != nil && (*rating < MinDiaryRating || *rating > MaxDiaryRating) {
+		return errors.New("rating must be between " + strconv.Itoa(MinDiaryRating) + " and " + strconv.Itoa(MaxDiaryRating))
+	}
+	return nil
+}
+
+// DiaryDateRange returns the first date of the year, or of the month if it is not zero, and the
+// first date after it.
+func DiaryDateRange(year, month int) (string, string) {
+	if month == 0 {
+		from := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+		return from.Format(DiaryDateLayout), from.AddDate(1, 0, 0).Format(DiaryDateLayout)
+	}
+	from := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+	return from.Format(DiaryDateLayout), from.AddDate(0, 1, 0).Format(DiaryDateLayout)
+}
+
+func (d DiaryEntry) Store(entry DiaryEntry) error {
+	coll := config.GetDmManager().Db.Collection(DiaryCollection)
+	_, err := coll.InsertOne(config.GetDmManager().Ctx, entry)
+	if err != nil {
+		log.Println("[ERROR] Insert document:", err.Error())
+		return err
+	}
+	return nil
+}
+
+// GetByID returns the diary entry of the user.
+func (d DiaryEntry) GetByID(userId, id string) DiaryEntry {
+	coll := config.GetDmManager().Db.Collection(DiaryCollection)
+	result := coll.FindOne(config.GetDmManager().Ctx, bson.M{"id": id, "user_id": userId})
+	res := new(DiaryEntry)
+	err := result.Decode(res)
+	if err != nil && err != mongo.ErrNoDocuments {
+		log.Println("[ERROR]", err)
+	}
+	return *res
+}
+
+// HasWatched returns true if the user logged a viewing of the movie on or before the date.
+func (d DiaryEntry) HasWatched(userId, movieId, date string) bool {
+	coll := config.GetDmManager().Db.Collection(DiaryCollection)
+	count, err := coll.CountDocuments(config.GetDmManager().Ctx, bson.M{
+		"user_id":    userId,
+		"movie.id":   movieId,
+		"watched_on": bson.M{"$lte": date},
+	}, options.Count().SetLimit(1))
+	if err != nil {
+		log.Println(err.Error())
+	}
+	return count > 0
+}
+
+// GetByUserId returns a page of diary entries of the user watched in the date range, latest
+// viewing first. Empty bounds are not applied.
+func (d DiaryEntry) GetByUserId(userId, from, to string, pagination Pagination) ([]DiaryEntry, int64) {
+	var data []DiaryEntry
+	query := diaryQuery(userId, from, to)
+	coll := config.GetDmManager().Db.Collection(DiaryCollection)
+	skip := pagination.Page * pagination.Limit
+	findOptions := options.FindOptions{
+		Limit: &pagination.Limit,
+		Skip:  &skip,
+		Sort:  bson.D{{Key: "watched_on", Value: -1}, {Key: "created_at", Value: -1}},
+	}
+	result, err := coll.Find(config.GetDmManager().Ctx, query, &findOptions)
+	if err != nil {
+		log.Println(err.Error())
+		return data, 0
+	}
+	for result.Next(context.TODO()) {
+		elemValue := new(DiaryEntry)
+		err := result.Decode(elemValue)
+		if err != nil {
+			log.Println("[ERROR]", err)
+			break
+		}
+		data = append(data, *elemValue)
+	}
+	count, err := coll.CountDocuments(config.GetDmManager().Ctx, query)
+	if err != nil {
+		log.Println(err.Error())
+	}
+	return data, count
+}
+
+// GetCalendar returns diary entries of the user watched in the date range grouped by day, in
+// date order. Days without entries are left out.
+func (d DiaryEntry) GetCalendar(userId, from, to string) []CalendarDay {
+	days := []CalendarDay{}
+	coll := config.GetDmManager().Db.Collection(DiaryCollection)
+	findOptions := options.Find().SetSort(bson.D{{Key: "watched_on", Value: 1}, {Key: "created_at", Value: 1}})
+	result, err := coll.Find(config.GetDmManager().Ctx, diaryQuery(userId, from, to), findOptions)
+	if err != nil {
+		log.Println(err.Error())
+		return days
+	}
+	for result.Next(context.TODO()) {
+		elemValue := new(DiaryEntry)
+		err := result.Decode(elemValue)
+		if err != nil {
+			log.Println("[ERROR]", err)
+			break
+		}
+		if len(days) == 0 || days[len(days)-1].Date != elemValue.WatchedOn {
+			days = append(days, CalendarDay{Date: elemValue.WatchedOn, Entries: []DiaryEntry{}})
+		}
+		days[len(days)-1].Entries = append(days[len(days)-1].Entries, *elemValue)
+	}
+	return days
+}
+
+func diaryQuery(userId, from, to string) bson.M {
+	query := bson.M{"user_id": userId}
+	watchedOn := bson.M{}
+	if from != "" {
+		watchedOn["$gte"] = from
+	}
+	if to != "" {
+		watchedOn["$lt"] = to
+	}
+	if len(watchedOn) > 0 {
+		query["watched_on"] = watchedOn
+	}
+	return query
+}
+
+// Update replaces the diary entry of the user.
+func (d DiaryEntry) Update(entry DiaryEntry) error {
+	coll := config.GetDmManager().Db.Collection(DiaryCollection)
+	res, err := coll.ReplaceOne(config.GetDmManager().Ctx, bson.M{"id": entry.ID, "user_id": entry.UserId}, entry)
+	if err != nil {
+		log.Println("[ERROR] Update document:", err.Error())
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return errors.New("diary entry not found")
+	}
+	return nil
+}
+
+// Delete removes the diary entry of the user.
+func (d DiaryEntry) Delete(userId, id string) error {
+	coll := config.GetDmManager().Db.Collection(DiaryCollection)
+	res, err := coll.DeleteOne(config.GetDmManager().Ctx, bson.M{"id": id, "user_id": userId})
+	if err != nil {
+		log.Println("[ERROR]", err)
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return errors.New("diary entry not found")
+	}
+	return nil
+}
+
+// UnlinkReview removes the review from diary entries linked to it.
+func (d DiaryEntry) UnlinkReview(reviewId string) error {
+	coll := config.GetDmManager().Db.Collection(DiaryCollection)
+	_, err := coll.UpdateMany(config.GetDmManager().Ctx, bson.M{"review_id": reviewId},
+		bson.M{"$unset": bson.M{"review_id": ""}, "$set": bson.M{"updated_at": time.Now().UTC()}})
+	if err != nil {
+		log.Println("[ERROR] Update document:", err.Error())
+		return err
+	}
+	return nil
+}
+
+// EnsureIndexes creates the index used by diary and calendar listings.
+func (d DiaryEntry) EnsureIndexes() error {
+	coll := config.GetDmManager().Db.Collection(DiaryCollection)
+	_, err := coll.Indexes().CreateOne(config.GetDmManager().Ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "watched_on", Value: -1}},
+	})
+	if err != nil {
+		log.Println("[ERROR] Create index:", err.Error())
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package v1
+
+import "strings"
+
+// TextChange describes how a single text field changed between two revisions.
+type TextChange struct {
+	Field  string   `json:"field" bson:"field"`
+	Before string   `json:"before" bson:"before"`
+	After  string   `json:"after" bson:"after"`
+	Diff   []string `json:"diff" bson:"diff"`
+}
+
+// DiffText returns the change of a field, or nil when the value did not change.
+func DiffText(field, before, after string) *TextChange {
+	if before == after {
+		return nil
+	}
+	return &TextChange{
+		Field:  field,
+		Before: before,
+		After:  after,
+		Diff:   DiffLines(before, after),
+	}
+}
+
+// DiffLines returns a line based diff of two texts. Removed lines are prefixed with "- ",
+// added lines with "+ " and unchanged lines with "  ".
+func DiffLines(before, after string) []string {
+	a := strings.Split(before, "\n")
+	b := strings.Split(after, "\n")
+	// lcs[i][j] holds the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	var diff []string
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		if a[i] == b[j] {
+			diff = append(diff, "  "+a[i])
+			i++
+			j++
+		} else if lcs[i+1][j] >= lcs[i][j+1] {
+			diff = append(diff, "- "+a[i])
+			i++
+		} else {
+			diff = append(diff, "+ "+b[j])
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		diff = append(diff, "- "+a[i])
+	}
+	for ; j < len(b); j++ {
+		diff = append(diff, "+ "+b[j])
+	}
+	return diff
+}
@@ -0,0 +1,162 @@
+package v1
+
+import (
+	"errors"
+	"github.com/niloydeb1/Golang-Movie_API/config"
+	"github.com/niloydeb1/Golang-Movie_API/enums"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"log"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const VerificationMailCollection = "verificationMailCollection"
+
+// ErrVerificationMailRateLimited is returned when a verification mail is requested too often.
+var ErrVerificationMailRateLimited = errors.New("verification mail is requested too often, please try again later")
+
+// VerificationMail contains a sent email verification mail, kept to rate limit resending.
+type VerificationMail struct {
+	UserId string    `json:"user_id" bson:"user_id"`
+	Email  string    `json:"email" bson:"email"`
+	SentAt time.Time `json:"sent_at" bson:"sent_at"`
+}
+
+// EmailVerificationDto contains data for resending a verification mail
+type EmailVerificationDto struct {
+	Email string `json:"email" bson:"email"`
+}
+
+// SendVerificationMail sends a mail with a link verifying the users email, unless the user
+// requested too many verification mails recently.
+func SendVerificationMail(mailer Mailer, user User) error {
+	now := time.Now().UTC()
+	if VerificationMailRateLimited(user.ID, now) {
+		return ErrVerificationMailRateLimited
+	}
+	lifetime := time.Duration(config.EmailVerificationTokenLifetimeHours) * time.Hour
+	token, err := Jwt{}.GenerateScopedToken(user.ID, enums.EMAIL_VERIFICATION, lifetime, map[string]string{"email": user.Email})
+	if err != nil {
+		return err
+	}
+	link := config.ApiBaseUrl + "/api/v1/users/verify?token=" + url.QueryEscape(token)
+	err = mailer.Send(Mail{
+		To:      user.Email,
+		Subject: "Verify your email",
+		Body: "Hi " + user.FirstName + ",\n\nPlease verify your email by opening the link below. The link expires in " +
+			strconv.FormatInt(config.EmailVerificationTokenLifetimeHours, 10) + " hours.\n\n" + link + "\n",
+	})
+	if err != nil {
+		return err
+	}
+	return VerificationMail{}.Store(VerificationMail{UserId: user.ID, Email: user.Email, SentAt: now})
+}
+
+// SendEmailChangeMail sends a mail to the new email with a link confirming the email change of the
+// user. It shares the rate limit of verification mails.
+func SendEmailChangeMail(mailer Mailer, user User, email string) error {
+	now := time.Now().UTC()
+	if VerificationMailRateLimited(user.ID, now) {
+		return ErrVerificationMailRateLimited
+	}
+	lifetime := time.Duration(config.EmailVerificationTokenLifetimeHours) * time.Hour
+	token, err := Jwt{}.GenerateScopedToken(user.ID, enums.EMAIL_CHANGE, lifetime, map[string]string{"email": email, "current_email": user.Email})
+	if err != nil {
+		return err
+	}
+	link := config.ApiBaseUrl + "/api/v1/users/me/email/verify?token=" + url.QueryEscape(token)
+	err = mailer.Send(Mail{
+		To:      email,
+		Subject: "Confirm your new email",
+		Body: "Hi " + user.FirstName + ",\n\nPlease confirm your new email by opening the link below. The link expires in " +
+			strconv.FormatInt(config.EmailVerificationTokenLifetimeHours, 10) + " hours.\n\n" + link + "\n",
+	})
+	if err != nil {
+		return err
+	}
+	return VerificationMail{}.Store(VerificationMail{UserId: user.ID, Email: email, SentAt: now})
+}
+
+// VerifyEmailChange replaces email of the user of a valid email change token with the new email. A
+// token is rejected if the user changed email or requested another change since it was issued.
+func VerifyEmailChange(token string) (User, error) {
+	userId, data, err := Jwt{}.ParseScopedToken(token, enums.EMAIL_CHANGE)
+	if err != nil {
+		return User{}, err
+	}
+	user := User{}.GetByID(userId)
+	if user.ID == "" || user.Status == enums.DELETED || user.Email != data["current_email"] || user.PendingEmail != data["email"] {
+		return User{}, errors.New("token does not belong to a pending email change")
+	}
+	if existing := (User{}).GetByEmail(data["email"]); existing.ID != "" {
+		return User{}, errors.New("email is already registered")
+	}
+	err = User{}.ChangeEmail(user.ID, data["email"])
+	if err != nil {
+		return User{}, err
+	}
+	user.Email, user.PendingEmail = data["email"], ""
+	return user, nil
+}
+
+// VerificationMailRateLimited returns true if the user has to wait before another verification mail is sent.
+func VerificationMailRateLimited(userId string, now time.Time) bool {
+	coll := config.GetDmManager().Db.Collection(VerificationMailCollection)
+	sentToday, err := coll.CountDocuments(config.GetDmManager().Ctx, bson.M{"user_id": userId, "sent_at": bson.M{"$gte": now.Add(-24 * time.Hour)}})
+	if err != nil {
+		log.Println(err.Error())
+	}
+	if sentToday >= config.VerificationMailDailyLimit {
+		return true
+	}
+	latest := VerificationMail{}.GetLatest(userId)
+	resendInterval := time.Duration(config.VerificationMailResendIntervalSeconds) * time.Second
+	return !latest.SentAt.IsZero() && now.Sub(latest.SentAt) < resendInterval
+}
+
+func (v VerificationMail) Store(mail VerificationMail) error {
+	coll := config.GetDmManager().Db.Collection(VerificationMailCollection)
+	_, err := coll.InsertOne(config.GetDmManager().Ctx, mail)
+	if err != nil {
+		log.Println("[ERROR] Insert document:", err.Error())
+		return err
+	}
+	return nil
+}
+
+// GetLatest returns the latest verification mail sent to the user.
+func (v VerificationMail) GetLatest(userId string) VerificationMail {
+	coll := config.GetDmManager().Db.Collection(VerificationMailCollection)
+	result := coll.FindOne(config.GetDmManager().Ctx, bson.M{"user_id": userId}, &options.FindOneOptions{Sort: bson.M{"sent_at": -1}})
+	res := new(VerificationMail)
+	err := result.Decode(res)
+	if err != nil && err != mongo.ErrNoDocuments {
+		log.Println("[ERROR]", err)
+	}
+	return *res
+}
+
+// VerifyEmail activates the user of a valid email verification token. A token is rejected if the
+// email of the user changed since it was issued.
+func VerifyEmail(token string) (User, error) {
+	userId, data, err := Jwt{}.ParseScopedToken(token, enums.EMAIL_VERIFICATION)
+	if err != nil {
+		return User{}, err
+	}
+	user := User{}.GetByID(userId)
+	if user.ID == "" || user.Status == enums.DELETED || user.Email != data["email"] {
+		return User{}, errors.New("token does not belong to a registered email")
+	}
+	if user.Status != enums.PENDING_VERIFICATION {
+		return user, nil
+	}
+	err = User{}.UpdateStatus(user.ID, enums.ACTIVE)
+	if err != nil {
+		return User{}, err
+	}
+	user.Status = enums.ACTIVE
+	return user, nil
+}
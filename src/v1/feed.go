@@ -0,0 +1,225 @@
+package v1
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"github.com/niloydeb1/Golang-Movie_API/config"
+	"github.com/niloydeb1/Golang-Movie_API/enums"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	ActivityCollection = "activityCollection"
+	FeedCollection     = "feedCollection"
+)
+
+const (
+	// FeedFanOutBatchSize is the number of feed items written at once when an activity is fanned out.
+	FeedFanOutBatchSize = 1000
+	// FeedBackfillLimit is the number of recent activities of a user added to a new followers feed.
+	FeedBackfillLimit = 50
+)
+
+// Activity is something a user did that is shown in feeds of their followers.
+type Activity struct {
+	ID         string              `json:"id" bson:"id"`
+	ActorId    string              `json:"actor_id" bson:"actor_id"`
+	Type       enums.ACTIVITY_TYPE `json:"type" bson:"type"`
+	TargetId   string              `json:"target_id" bson:"target_id"`
+	MovieTitle string              `json:"movie_title,omitempty" bson:"movie_title,omitempty"`
+	Summary    string              `json:"summary" bson:"summary"`
+	CreatedAt  time.Time           `json:"created_at" bson:"created_at"`
+}
+
+// FeedItem is an activity delivered to the feed of a follower of its actor.
+type FeedItem struct {
+	UserId   string `json:"-" bson:"user_id"`
+	Activity `bson:",inline"`
+}
+
+// FeedEntry is an activity of a feed with its actor.
+type FeedEntry struct {
+	Activity
+	Actor UserPublicView `json:"actor"`
+}
+
+// FeedCursor points to the position after an activity in a feed.
+type FeedCursor struct {
+	CreatedAt time.Time
+	ID        string
+}
+
+// Encode returns the opaque representation of the cursor.
+func (c FeedCursor) Encode() string {
+	raw := strconv.FormatInt(c.CreatedAt.UnixMilli(), 10) + ":" + c.ID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeFeedCursor returns the cursor of its opaque representation.
+func DecodeFeedCursor(cursor string) (FeedCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return FeedCursor{}, errors.New("invalid cursor")
+	}
+	parts := strings.SplitN(string(raw), ":", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return FeedCursor{}, errors.New("invalid cursor")
+	}
+	millis, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return FeedCursor{}, errors.New("invalid cursor")
+	}
+	return FeedCursor{CreatedAt: time.UnixMilli(millis).UTC(), ID: parts[1]}, nil
+}
+
+// Publish stores the activity and delivers it to feeds of all followers of its actor, in batches
+// so actors with many followers do not hold all of them in memory.
+func (a Activity) Publish(activity Activity) error {
+	activity.CreatedAt = activity.CreatedAt.Truncate(time.Millisecond)
+	coll := config.GetDmManager().Db.Collection(ActivityCollection)
+	_, err := coll.InsertOne(config.GetDmManager().Ctx, activity)
+	if err != nil {
+		log.Println("[ERROR] Insert document:", err.Error())
+		return err
+	}
+	return Follow{}.EachFollowerId(activity.ActorId, FeedFanOutBatchSize, func(followerIds []string) error {
+		items := make([]interface{}, 0, len(followerIds))
+		for _, followerId := range followerIds {
+			items = append(items, FeedItem{UserId: followerId, Activity: activity})
+		}
+		return insertFeedItems(items)
+	})
+}
+
+// Backfill delivers recent activities of the followee to the feed of a new follower.
+func (a Activity) Backfill(followerId, followeeId string) error {
+	coll := config.GetDmManager().Db.Collection(ActivityCollection)
+	findOptions := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}).SetLimit(FeedBackfillLimit)
+	result, err := coll.Find(config.GetDmManager().Ctx, bson.M{"actor_id": followeeId}, findOptions)
+	if err != nil {
+		log.Println(err.Error())
+		return err
+	}
+	var items []interface{}
+	for result.Next(context.TODO()) {
+		elemValue := Activity{}
+		if err := result.Decode(&elemValue); err != nil {
+			log.Println("[ERROR]", err)
+			return err
+		}
+		items = append(items, FeedItem{UserId: followerId, Activity: elemValue})
+	}
+	if len(items) == 0 {
+		return nil
+	}
+	return insertFeedItems(items)
+}
+
+// insertFeedItems stores feed items, skipping items already in a feed.
+func insertFeedItems(items []interface{}) error {
+	coll := config.GetDmManager().Db.Collection(FeedCollection)
+	_, err := coll.InsertMany(config.GetDmManager().Ctx, items, options.InsertMany().SetOrdered(false))
+	if err != nil && !mongo.IsDuplicateKeyError(err) {
+		log.Println("[ERROR] Insert document:", err.Error())
+		return err
+	}
+	return nil
+}
+
+// DeleteByTarget removes activities of the target from the activity log and every feed.
+func (a Activity) DeleteByTarget(activityType enums.ACTIVITY_TYPE, targetId string) error {
+	query := bson.M{"type": activityType, "target_id": targetId}
+	_, err := config.GetDmManager().Db.Collection(ActivityCollection).DeleteMany(config.GetDmManager().Ctx, query)
+	if err != nil {
+		log.Println("[ERROR]", err)
+		return err
+	}
+	_, err = config.GetDmManager().Db.Collection(FeedCollection).DeleteMany(config.GetDmManager().Ctx, query)
+	if err != nil {
+		log.Println("[ERROR]", err)
+		return err
+	}
+	return nil
+}
+
+// RemoveActor removes activities of the actor from the feed of the user.
+func (f FeedItem) RemoveActor(userId, actorId string) error {
+	coll := config.GetDmManager().Db.Collection(FeedCollection)
+	_, err := coll.DeleteMany(config.GetDmManager().Ctx, bson.M{"user_id": userId, "actor_id": actorId})
+	if err != nil {
+		log.Println("[ERROR]", err)
+		return err
+	}
+	return nil
+}
+
+// GetFeed returns up to limit activities of the feed of the user after the cursor, latest first,
+// skipping activities of hidden actors, and the cursor of the next page. The next cursor is nil
+// on the last page.
+func (f FeedItem) GetFeed(userId string, cursor *FeedCursor, limit int64, hiddenActorIds []string) ([]Activity, *FeedCursor) {
+	data := []Activity{}
+	query := bson.M{"user_id": userId}
+	if len(hiddenActorIds) > 0 {
+		query["actor_id"] = bson.M{"$nin": hiddenActorIds}
+	}
+	if cursor != nil {
+		query["$or"] = []bson.M{
+			{"created_at": bson.M{"$lt": cursor.CreatedAt}},
+			{"created_at": cursor.CreatedAt, "id": bson.M{"$lt": cursor.ID}},
+		}
+	}
+	coll := config.GetDmManager().Db.Collection(FeedCollection)
+	findOptions := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "id", Value: -1}}).SetLimit(limit + 1)
+	result, err := coll.Find(config.GetDmManager().Ctx, query, findOptions)
+	if err != nil {
+		log.Println(err.Error())
+		return data, nil
+	}
+	for result.Next(context.TODO()) {
+		elemValue := FeedItem{}
+		err := result.Decode(&elemValue)
+		if err != nil {
+			log.Println("[ERROR]", err)
+			break
+		}
+		data = append(data, elemValue.Activity)
+	}
+	if int64(len(data)) <= limit {
+		return data, nil
+	}
+	data = data[:limit]
+	last := data[len(data)-1]
+	return data, &FeedCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+}
+
+// EnsureIndexes creates indexes used by feed delivery and reading.
+func (a Activity) EnsureIndexes() error {
+	_, err := config.GetDmManager().Db.Collection(ActivityCollection).Indexes().CreateMany(config.GetDmManager().Ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "actor_id", Value: 1}, {Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "type", Value: 1}, {Key: "target_id", Value: 1}}},
+	})
+	if err != nil {
+		log.Println("[ERROR] Create index:", err.Error())
+		return err
+	}
+	_, err = config.GetDmManager().Db.Collection(FeedCollection).Indexes().CreateMany(config.GetDmManager().Ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}, {Key: "id", Value: -1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "actor_id", Value: 1}}},
+		{Keys: bson.D{{Key: "type", Value: 1}, {Key: "target_id", Value: 1}}},
+	})
+	if err != nil {
+		log.Println("[ERROR] Create index:", err.Error())
+		return err
+	}
+	return nil
+}
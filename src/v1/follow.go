@@ -0,0 +1,179 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"github.com/niloydeb1/Golang-Movie_API/config"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"log"
+	"time"
+)
+
+const FollowCollection = "followCollection"
+
+// ErrAlreadyFollowing is returned when the user already follows the other user.
+var ErrAlreadyFollowing = errors.New("user is already followed")
+
+// Follow is a user following another user.
+type Follow struct {
+	FollowerId string    `json:"follower_id" bson:"follower_id"`
+	FolloweeId string    `json:"followee_id" bson:"followee_id"`
+	CreatedAt  time.Time `json:"created_at" bson:"created_at"`
+}
+
+// FollowView is a user of a follower or following listing.
+type FollowView struct {
+	User       UserPublicView `json:"user"`
+	FollowedAt time.Time      `json:"followed_at"`
+}
+
+// Store stores the follow. It returns ErrAlreadyFollowing if the follow exists.
+func (f Follow) Store(follow Follow) error {
+	coll := config.GetDmManager().Db.Collection(FollowCollection)
+	_, err := coll.InsertOne(config.GetDmManager().Ctx, follow)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrAlreadyFollowing
+	}
+	if err != nil {
+		log.Println("[ERROR] Insert document:", err.Error())
+		return err
+	}
+	return nil
+}
+
+// Delete removes the follow of the followee by the follower.
+func (f Follow) Delete(followerId, followeeId string) error {
+	coll := config.GetDmManager().Db.Collection(FollowCollection)
+	res, err := coll.DeleteOne(config.GetDmManager().Ctx, bson.M{"follower_id": followerId, "followee_id": followeeId})
+	if err != nil {
+		log.Println("[ERROR]", err)
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return errors.New("user is not followed")
+	}
+	return nil
+}
+
+// IsFollowing returns true if the follower follows the followee.
+func (f Follow) IsFollowing(followerId, followeeId string) bool {
+	coll := config.GetDmManager().Db.Collection(FollowCollection)
+	count, err := coll.CountDocuments(config.GetDmManager().Ctx, bson.M{"follower_id": followerId, "followee_id": followeeId})
+	if err != nil {
+		log.Println(err.Error())
+	}
+	return count > 0
+}
+
+// CountFollowers returns number of followers of the user.
+func (f Follow) CountFollowers(userId string) int64 {
+	return f.count(bson.M{"followee_id": userId})
+}
+
+// CountFollowing returns number of users the user follows.
+func (f Follow) CountFollowing(userId string) int64 {
+	return f.count(bson.M{"follower_id": userId})
+}
+
+func (f Follow) count(query bson.M) int64 {
+	coll := config.GetDmManager().Db.Collection(FollowCollection)
+	count, err := coll.CountDocuments(config.GetDmManager().Ctx, query)
+	if err != nil {
+		log.Println(err.Error())
+	}
+	return count
+}
+
+// GetFollowers returns a page of follows of the user, latest first, leaving out the excluded followers.
+func (f Follow) GetFollowers(userId string, excludedUserIds []string, pagination Pagination) ([]Follow, int64) {
+	return f.search(ExcludeUsersQuery(bson.M{"followee_id": userId}, "follower_id", excludedUserIds), pagination)
+}
+
+// GetFollowing returns a page of follows by the user, latest first, leaving out the excluded followees.
+func (f Follow) GetFollowing(userId string, excludedUserIds []string, pagination Pagination) ([]Follow, int64) {
+	return f.search(ExcludeUsersQuery(bson.M{"follower_id": userId}, "followee_id", excludedUserIds), pagination)
+}
+
+func (f Follow) search(query bson.M, pagination Pagination) ([]Follow, int64) {
+	var data []Follow
+	coll := config.GetDmManager().Db.Collection(FollowCollection)
+	skip := pagination.Page * pagination.Limit
+	findOptions := options.FindOptions{
+		Limit: &pagination.Limit,
+		Skip:  &skip,
+		Sort:  bson.D{{Key: "created_at", Value: -1}},
+	}
+	result, err := coll.Find(config.GetDmManager().Ctx, query, &findOptions)
+	if err != nil {
+		log.Println(err.Error())
+		return data, 0
+	}
+	for result.Next(context.TODO()) {
+		elemValue := new(Follow)
+		err := result.Decode(elemValue)
+		if err != nil {
+			log.Println("[ERROR]", err)
+			break
+		}
+		data = append(data, *elemValue)
+	}
+	count, err := coll.CountDocuments(config.GetDmManager().Ctx, query)
+	if err != nil {
+		log.Println(err.Error())
+	}
+	return data, count
+}
+
+// EachFollowerId calls fn with ids of followers of the user in batches of the given size, without
+// loading all of them at once. It stops at the first error returned by fn.
+func (f Follow) EachFollowerId(userId string, batchSize int, fn func([]string) error) error {
+	coll := config.GetDmManager().Db.Collection(FollowCollection)
+	findOptions := options.Find().SetProjection(bson.M{"follower_id": 1}).SetBatchSize(int32(batchSize))
+	result, err := coll.Find(config.GetDmManager().Ctx, bson.M{"followee_id": userId}, findOptions)
+	if err != nil {
+		log.Println(err.Error())
+		return err
+	}
+	defer result.Close(context.TODO())
+	batch := make([]string, 0, batchSize)
+	for result.Next(context.TODO()) {
+		elemValue := Follow{}
+		if err := result.Decode(&elemValue); err != nil {
+			log.Println("[ERROR]", err)
+			return err
+		}
+		batch = append(batch, elemValue.FollowerId)
+		if len(batch) == batchSize {
+			if err := fn(batch); err != nil {
+				return err
+			}
+			batch = make([]string, 0, batchSize)
+		}
+	}
+	if len(batch) > 0 {
+		if err := fn(batch); err != nil {
+			return err
+		}
+	}
+	return result.Err()
+}
+
+// EnsureIndexes creates the index that keeps a follow unique and indexes used by follow listings.
+func (f Follow) EnsureIndexes() error {
+	coll := config.GetDmManager().Db.Collection(FollowCollection)
+	_, err := coll.Indexes().CreateMany(config.GetDmManager().Ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "follower_id", Value: 1}, {Key: "followee_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{Keys: bson.D{{Key: "follower_id", Value: 1}, {Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "followee_id", Value: 1}, {Key: "created_at", Value: -1}}},
+	})
+	if err != nil {
+		log.Println("[ERROR] Create index:", err.Error())
+		return err
+	}
+	return nil
+}
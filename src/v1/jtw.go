@@ -5,8 +5,10 @@ import (
 	"crypto/x509"
 	"encoding/json"
 	"encoding/pem"
+	"errors"
 	"github.com/golang-jwt/jwt"
 	"github.com/niloydeb1/Golang-Movie_API/config"
+	"github.com/niloydeb1/Golang-Movie_API/enums"
 	"log"
 	"time"
 )
@@ -53,6 +55,69 @@ func (j Jwt) GenerateToken(userUUID string, duration int64, data interface{}) (s
 	return tokenString, refreshTokenStr, nil
 }
 
+// GenerateScopedToken returns a signed token of the subject that is only accepted for the scope.
+func (j Jwt) GenerateScopedToken(subject string, scope enums.TOKEN_SCOPE, lifetime time.Duration, data map[string]string) (string, error) {
+	token := jwt.New(jwt.SigningMethodRS512)
+	token.Claims = jwt.MapClaims{
+		"exp":   time.Now().UTC().Add(lifetime).Unix(),
+		"iat":   time.Now().UTC().Unix(),
+		"sub":   subject,
+		"scope": string(scope),
+		"data":  data,
+	}
+	return token.SignedString(j.GetRsaKeys().PrivateKey)
+}
+
+// ParseScopedToken returns the subject and data of a token signed for the scope. It returns an
+// error if the token is invalid, expired or of another scope.
+func (j Jwt) ParseScopedToken(tokenString string, scope enums.TOKEN_SCOPE) (string, map[string]string, error) {
+	claims := jwt.MapClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
+			return nil, errors.New("unexpected signing method")
+		}
+		return j.GetRsaKeys().PublicKey, nil
+	})
+	if err != nil || !token.Valid {
+		return "", nil, errors.New("token is invalid or expired")
+	}
+	if claims["scope"] != string(scope) {
+		return "", nil, errors.New("token is not issued for " + string(scope))
+	}
+	subject, _ := claims["sub"].(string)
+	data := make(map[string]string)
+	if values, ok := claims["data"].(map[string]interface{}); ok {
+		for key, value := range values {
+			if text, ok := value.(string); ok {
+				data[key] = text
+			}
+		}
+	}
+	return subject, data, nil
+}
+
+// GetTokenTimes returns when the token was issued and when it expires. Zero times are returned for
+// claims the token does not have.
+func (j Jwt) GetTokenTimes(tokenString string) (time.Time, time.Time) {
+	claims := jwt.MapClaims{}
+	jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		return j.GetRsaKeys().PublicKey, nil
+	})
+	return claimTime(claims["iat"]), claimTime(claims["exp"])
+}
+
+// claimTime returns time of a numeric date claim.
+func claimTime(claim interface{}) time.Time {
+	switch value := claim.(type) {
+	case float64:
+		return time.Unix(int64(value), 0).UTC()
+	case json.Number:
+		v, _ := value.Int64()
+		return time.Unix(v, 0).UTC()
+	}
+	return time.Time{}
+}
+
 func (j Jwt) IsTokenValid(tokenString string) bool {
 	claims := jwt.MapClaims{}
 	jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
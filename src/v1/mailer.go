@@ -0,0 +1,102 @@
+package v1
+
+import (
+	"errors"
+	"github.com/niloydeb1/Golang-Movie_API/config"
+	"github.com/niloydeb1/Golang-Movie_API/enums"
+	"log"
+	"net/smtp"
+	"os"
+	"strings"
+	"time"
+)
+
+// Mail contains a plain text mail.
+type Mail struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers mails.
+type Mailer interface {
+	Send(mail Mail) error
+}
+
+// SmtpMailer delivers mails through an smtp server.
+type SmtpMailer struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+// LogMailer writes mails to a file, or to log if no path is set. It is meant for local use.
+type LogMailer struct {
+	Path string
+}
+
+// NewMailer returns the mailer configured by environment.
+func NewMailer() Mailer {
+	if config.Mailer == enums.SMTP_MAILER {
+		return SmtpMailer{
+			Host:     config.SmtpHost,
+			Port:     config.SmtpPort,
+			Username: config.SmtpUsername,
+			Password: config.SmtpPassword,
+			From:     config.MailFrom,
+		}
+	}
+	return LogMailer{Path: config.MailLogPath}
+}
+
+func (m SmtpMailer) Send(mail Mail) error {
+	if m.Host == "" || m.From == "" {
+		return errors.New("smtp host and sender are not configured")
+	}
+	var auth smtp.Auth
+	if m.Username != "" {
+		auth = smtp.PlainAuth("", m.Username, m.Password, m.Host)
+	}
+	err := smtp.SendMail(m.Host+":"+m.Port, auth, m.From, []string{mail.To}, mail.message(m.From))
+	if err != nil {
+		log.Println("[ERROR] Send mail:", err.Error())
+		return err
+	}
+	return nil
+}
+
+func (m LogMailer) Send(mail Mail) error {
+	if m.Path == "" {
+		log.Println("[MAIL] To:", mail.To, "Subject:", mail.Subject, "\n"+mail.Body)
+		return nil
+	}
+	file, err := os.OpenFile(m.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		log.Println("[ERROR] Open mail log:", err.Error())
+		return err
+	}
+	defer file.Close()
+	_, err = file.Write(append(mail.message(config.MailFrom), "\r\n"...))
+	if err != nil {
+		log.Println("[ERROR] Write mail log:", err.Error())
+		return err
+	}
+	return nil
+}
+
+// message returns the mail formatted as an RFC 5322 message.
+func (m Mail) message(from string) []byte {
+	header := strings.NewReplacer("\r", "", "\n", "")
+	var message strings.Builder
+	message.WriteString("From: " + header.Replace(from) + "\r\n")
+	message.WriteString("To: " + header.Replace(m.To) + "\r\n")
+	message.WriteString("Subject: " + header.Replace(m.Subject) + "\r\n")
+	message.WriteString("Date: " + time.Now().UTC().Format(time.RFC1123Z) + "\r\n")
+	message.WriteString("MIME-Version: 1.0\r\n")
+	message.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	message.WriteString("\r\n")
+	message.WriteString(strings.ReplaceAll(m.Body, "\n", "\r\n"))
+	return []byte(message.String())
+}
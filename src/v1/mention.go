@@ -0,0 +1,108 @@
+package v1
+
+import (
+	"github.com/niloydeb1/Golang-Movie_API/config"
+	"github.com/niloydeb1/Golang-Movie_API/enums"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"log"
+	"regexp"
+	"strings"
+)
+
+// mentionPattern matches mentions written as @ followed by the email of a user, e.g. @jane@example.com.
+var mentionPattern = regexp.MustCompile(`\B@([A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)+)`)
+
+// Mention contains a user mentioned in a review or comment. The email is not exposed.
+type Mention struct {
+	UserId string `json:"user_id" bson:"user_id"`
+	Name   string `json:"name" bson:"name"`
+	Email  string `json:"-" bson:"email"`
+}
+
+// ResolveMentions returns the active users mentioned in the text. Mentions of unknown emails and
+// of users who are not active are ignored.
+func ResolveMentions(text string) []Mention {
+	mentions := []Mention{}
+	resolved := make(map[string]bool)
+	for _, match := range mentionPattern.FindAllStringSubmatch(text, -1) {
+		email := match[1]
+		if resolved[strings.ToLower(email)] {
+			continue
+		}
+		resolved[strings.ToLower(email)] = true
+		user := User{}.GetByEmail(email)
+		if user.ID == "" || user.Status != enums.ACTIVE {
+			continue
+		}
+		mentions = append(mentions, Mention{UserId: user.ID, Name: strings.TrimSpace(user.FirstName + " " + user.LastName), Email: email})
+	}
+	return mentions
+}
+
+// RenderMentions returns the text with every resolved mention replaced by a markdown link to the
+// profile of the mentioned user, named by the user instead of the email.
+func RenderMentions(text string, mentions []Mention) string {
+	if len(mentions) == 0 {
+		return text
+	}
+	links := make(map[string]Mention)
+	for _, mention := range mentions {
+		links[strings.ToLower(mention.Email)] = mention
+	}
+	return mentionPattern.ReplaceAllStringFunc(text, func(match string) string {
+		mention, ok := links[strings.ToLower(match[1:])]
+		if !ok {
+			return match
+		}
+		return "[@" + mention.Name + "](/api/v1/users/" + mention.UserId + "/profile)"
+	})
+}
+
+// WithoutBlockers returns the mentions leaving out users who blocked the author.
+func WithoutBlockers(authorId string, mentions []Mention) []Mention {
+	if len(mentions) == 0 {
+		return mentions
+	}
+	userIds := make([]string, len(mentions))
+	for i, mention := range mentions {
+		userIds[i] = mention.UserId
+	}
+	blockers := UserRelation{}.BlockersOf(authorId, userIds)
+	allowed := []Mention{}
+	for _, mention := range mentions {
+		if !blockers[mention.UserId] {
+			allowed = append(allowed, mention)
+		}
+	}
+	return allowed
+}
+
+// updateMentionEmail sets the email of mentions of the user in documents of the collection.
+func updateMentionEmail(collection, userId, email string) error {
+	coll := config.GetDmManager().Db.Collection(collection)
+	_, err := coll.UpdateMany(config.GetDmManager().Ctx,
+		bson.M{"mentions.user_id": userId},
+		bson.M{"$set": bson.M{"mentions.$[mention].email": email}},
+		options.Update().SetArrayFilters(options.ArrayFilters{Filters: []interface{}{bson.M{"mention.user_id": userId}}}))
+	if err != nil {
+		log.Println("[ERROR] Update document:", err.Error())
+		return err
+	}
+	return nil
+}
+
+// NewMentions returns the mentions that are not in the previous mentions.
+func NewMentions(previous, mentions []Mention) []Mention {
+	known := make(map[string]bool)
+	for _, mention := range previous {
+		known[mention.UserId] = true
+	}
+	var added []Mention
+	for _, mention := range mentions {
+		if !known[mention.UserId] {
+			added = append(added, mention)
+		}
+	}
+	return added
+}
@@ -0,0 +1,72 @@
+package v1
+
+import (
+	"errors"
+	"github.com/niloydeb1/Golang-Movie_API/config"
+	"github.com/niloydeb1/Golang-Movie_API/enums"
+	"go.mongodb.org/mongo-driver/bson"
+	"time"
+)
+
+// Moderation contains moderation state of a review or comment.
+// Content stored before moderation existed has no state and counts as approved.
+type Moderation struct {
+	State       enums.MODERATION_STATE `json:"state" bson:"state"`
+	Reason      string                 `json:"reason,omitempty" bson:"reason,omitempty"`
+	ModeratorId string                 `json:"moderator_id,omitempty" bson:"moderator_id,omitempty"`
+	ModeratedAt *time.Time             `json:"moderated_at,omitempty" bson:"moderated_at,omitempty"`
+}
+
+// ModerationDto contains data for a moderation decision
+type ModerationDto struct {
+	State  enums.MODERATION_STATE `json:"state" bson:"state"`
+	Reason string                 `json:"reason" bson:"reason"`
+}
+
+// Validate validates ModerationDto data
+func (m ModerationDto) Validate() error {
+	if !IsValidModerationState(m.State) {
+		return errors.New("state must be one of [pending/approved/rejected/hidden]")
+	}
+	if (m.State == enums.REJECTED || m.State == enums.HIDDEN) && m.Reason == "" {
+		return errors.New("reason is required to reject or hide content")
+	}
+	return nil
+}
+
+// IsValidModerationState returns true if the state is a known moderation state.
+func IsValidModerationState(state enums.MODERATION_STATE) bool {
+	switch state {
+	case enums.PENDING, enums.APPROVED, enums.REJECTED, enums.HIDDEN:
+		return true
+	}
+	return false
+}
+
+// IsVisible returns true if the content is visible to everyone.
+func (m Moderation) IsVisible() bool {
+	return m.State == "" || m.State == enums.APPROVED
+}
+
+// NewModeration returns moderation of new content of the author according to moderation policy.
+func NewModeration(author User) Moderation {
+	switch config.ModerationPolicy {
+	case enums.MODERATE_ALL:
+		return Moderation{State: enums.PENDING}
+	case enums.MODERATE_NEW_ACCOUNTS:
+		newAccountAge := time.Duration(config.ModerationNewAccountDays) * 24 * time.Hour
+		if author.Role == enums.USER && time.Now().UTC().Sub(author.CreatedDate) < newAccountAge {
+			return Moderation{State: enums.PENDING}
+		}
+	}
+	return Moderation{State: enums.APPROVED}
+}
+
+// VisibleQuery restricts the query to content visible to everyone.
+func VisibleQuery(query bson.M) bson.M {
+	visible := bson.M{"moderation.state": bson.M{"$nin": []enums.MODERATION_STATE{enums.PENDING, enums.REJECTED, enums.HIDDEN}}}
+	if len(query) == 0 {
+		return visible
+	}
+	return bson.M{"$and": []bson.M{query, visible}}
+}
@@ -6,10 +6,37 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"log"
+	"strconv"
 )
 
 const MovieCollection = "movieCollection"
 
+// MovieSummary contains the movie details kept with entries that refer to a movie.
+type MovieSummary struct {
+	ID     string  `json:"id" bson:"id"`
+	Title  string  `json:"title" bson:"title"`
+	Year   int64   `json:"year" bson:"year"`
+	Genre  string  `json:"genre" bson:"genre"`
+	Poster string  `json:"poster" bson:"poster"`
+	Rating float64 `json:"rating" bson:"rating"`
+}
+
+// NewMovieSummary returns summary of the movie. Year is the first year the movie was released
+// and rating is its imdb rating, both are zero if unknown.
+func NewMovieSummary(movie Movie) MovieSummary {
+	summary := MovieSummary{
+		ID:     movie.ID,
+		Title:  movie.Title,
+		Genre:  movie.Genre,
+		Poster: movie.Poster,
+	}
+	if len(movie.Year) >= 4 {
+		summary.Year, _ = strconv.ParseInt(movie.Year[:4], 10, 64)
+	}
+	summary.Rating, _ = strconv.ParseFloat(movie.ImdbRating, 64)
+	return summary
+}
+
 type Movie struct {
 	ID         string `json:"id" bson:"id"`
 	Title      string `json:"Title" bson:"Title"`
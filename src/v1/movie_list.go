@@ -0,0 +1,344 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"github.com/niloydeb1/Golang-Movie_API/config"
+	"github.com/niloydeb1/Golang-Movie_API/enums"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const MovieListCollection = "movieListCollection"
+
+const (
+	// MovieListNameMaxLength is the maximum length of the name of a movie list.
+	MovieListNameMaxLength = 100
+	// MovieListDescriptionMaxLength is the maximum length of the description of a movie list.
+	MovieListDescriptionMaxLength = 2000
+	// MovieListMaxEntries is the maximum number of movies on a movie list.
+	MovieListMaxEntries = 500
+)
+
+var (
+	// ErrMovieListEntryExists is returned when the movie is already on the list.
+	ErrMovieListEntryExists = errors.New("movie is already on the list")
+	// ErrMovieListEntryNotFound is returned when the movie is not on the list.
+	ErrMovieListEntryNotFound = errors.New("movie is not on the list")
+	// ErrMovieListFull is returned when the list already has the maximum number of movies.
+	ErrMovieListFull = errors.New("list can have at most " + strconv.Itoa(MovieListMaxEntries) + " movies")
+)
+
+// MovieList is a named, ordered list of movies curated by a user.
+type MovieList struct {
+	ID          string                `json:"id" bson:"id"`
+	OwnerId     string                `json:"owner_id" bson:"owner_id"`
+	Name        string                `json:"name" bson:"name"`
+	Description string                `json:"description" bson:"description"`
+	Visibility  enums.LIST_VISIBILITY `json:"visibility" bson:"visibility"`
+	Ranked      bool                  `json:"ranked" bson:"ranked"`
+	Entries     []MovieListEntry      `json:"entries" bson:"entries"`
+	EntryCount  int64                 `json:"entry_count" bson:"entry_count"`
+	Featured    bool                  `json:"featured" bson:"featured"`
+	FeaturedAt  *time.Time            `json:"featured_at,omitempty" bson:"featured_at,omitempty"`
+	CreatedAt   time.Time             `json:"created_at" bson:"created_at"`
+	UpdatedAt   time.Time             `json:"updated_at" bson:"updated_at"`
+}
+
+// MovieListEntry is a movie on a list. Rank is its position on a ranked list.
+type MovieListEntry struct {
+	Rank    int64        `json:"rank,omitempty" bson:"-"`
+	Movie   MovieSummary `json:"movie" bson:"movie"`
+	Notes   string       `json:"notes" bson:"notes"`
+	AddedAt time.Time    `json:"added_at" bson:"added_at"`
+}
+
+// MovieListDto contains data for creating or updating a movie list, nil fields are left unchanged on update.
+type MovieListDto struct {
+	Name        *string                `json:"name" bson:"name"`
+	Description *string                `json:"description" bson:"description"`
+	Visibility  *enums.LIST_VISIBILITY `json:"visibility" bson:"visibility"`
+	Ranked      *bool                  `json:"ranked" bson:"ranked"`
+}
+
+// MovieListEntryDto contains data for adding a movie to a list by movie id or title. Position is
+// the 1 based position of the movie on the list, the movie is appended if it is zero.
+type MovieListEntryDto struct {
+	MovieId  string `json:"movie_id" bson:"movie_id"`
+	Title    string `json:"title" bson:"title"`
+	Notes    string `json:"notes" bson:"notes"`
+	Position int64  `json:"position" bson:"position"`
+}
+
+// MovieListOrderDto contains movie ids of a list in their new order.
+type MovieListOrderDto struct {
+	MovieIds []string `json:"movie_ids" bson:"movie_ids"`
+}
+
+// Validate validates MovieListDto data. Name is required if the list is created.
+func (m MovieListDto) Validate(create bool) error {
+	if m.Name != nil {
+		name := strings.TrimSpace(*m.Name)
+		if name == "" {
+			return errors.New("name is required")
+		}
+		if len([]rune(name)) > MovieListNameMaxLength {
+			return errors.New("name must be at most " + strconv.Itoa(MovieListNameMaxLength) + " characters")
+		}
+	} else if create {
+		return errors.New("name is required")
+	}
+	if m.Description != nil && len([]rune(*m.Description)) > MovieListDescriptionMaxLength {
+		return errors.New("description must be at most " + strconv.Itoa(MovieListDescriptionMaxLength) + " characters")
+	}
+	if m.Visibility != nil {
+		switch *m.Visibility {
+		case enums.PUBLIC_LIST, enums.UNLISTED_LIST, enums.PRIVATE_LIST:
+		default:
+			return errors.New("visibility must be one of [public/unlisted/private]")
+		}
+	}
+	return nil
+}
+
+// Validate validates MovieListEntryDto data
+func (m MovieListEntryDto) Validate() error {
+	if m.MovieId == "" && m.Title == "" {
+		return errors.New("movie id or title is required")
+	}
+	if m.Position < 0 {
+		return errors.New("position must be positive")
+	}
+	return validateWatchlistNotes(m.Notes)
+}
+
+// IsVisibleTo returns true if the user can see the list. Private lists are seen only by their owner.
+func (m MovieList) IsVisibleTo(userId string) bool {
+	return m.Visibility != enums.PRIVATE_LIST || m.OwnerId == userId
+}
+
+// WithRanks returns the list with ranks of entries set if the list is ranked.
+func (m MovieList) WithRanks() MovieList {
+	entries := make([]MovieListEntry, len(m.Entries))
+	for i, entry := range m.Entries {
+		entry.Rank = 0
+		if m.Ranked {
+			entry.Rank = int64(i + 1)
+		}
+		entries[i] = entry
+	}
+	m.Entries = entries
+	return m
+}
+
+// IndexOf returns position of the movie on the list, -1 if it is not on the list.
+func (m MovieList) IndexOf(movieId string) int {
+	for i, entry := range m.Entries {
+		if entry.Movie.ID == movieId {
+			return i
+		}
+	}
+	return -1
+}
+
+// AddEntry returns the list with the entry inserted at the 1 based position, or appended if the
+// position is zero or beyond the end of the list.
+func (m MovieList) AddEntry(entry MovieListEntry, position int64) (MovieList, error) {
+	if m.IndexOf(entry.Movie.ID) >= 0 {
+		return m, ErrMovieListEntryExists
+	}
+	if len(m.Entries) >= MovieListMaxEntries {
+		return m, ErrMovieListFull
+	}
+	entries := make([]MovieListEntry, 0, len(m.Entries)+1)
+	index := len(m.Entries)
+	if position > 0 && position <= int64(len(m.Entries)) {
+		index = int(position - 1)
+	}
+	entries = append(entries, m.Entries[:index]...)
+	entries = append(entries, entry)
+	entries = append(entries, m.Entries[index:]...)
+	m.Entries = entries
+	return m, nil
+}
+
+// RemoveEntry returns the list without the movie.
+func (m MovieList) RemoveEntry(movieId string) (MovieList, error) {
+	index := m.IndexOf(movieId)
+	if index < 0 {
+		return m, ErrMovieListEntryNotFound
+	}
+	entries := make([]MovieListEntry, 0, len(m.Entries)-1)
+	entries = append(entries, m.Entries[:index]...)
+	entries = append(entries, m.Entries[index+1:]...)
+	m.Entries = entries
+	return m, nil
+}
+
+// Reorder returns the list with entries in the order of the movie ids, which must contain every
+// movie of the list exactly once.
+func (m MovieList) Reorder(movieIds []string) (MovieList, error) {
+	if len(movieIds) != len(m.Entries) {
+		return m, errors.New("movie ids must contain every movie of the list exactly once")
+	}
+	entries := make([]MovieListEntry, 0, len(m.Entries))
+	seen := map[string]bool{}
+	for _, movieId := range movieIds {
+		index := m.IndexOf(movieId)
+		if index < 0 || seen[movieId] {
+			return m, errors.New("movie ids must contain every movie of the list exactly once")
+		}
+		seen[movieId] = true
+		entries = append(entries, m.Entries[index])
+	}
+	m.Entries = entries
+	return m, nil
+}
+
+// MovieListQuery returns the query of lists shown in list search. Only public lists are searched,
+// unless the owner searches own lists. Name matches the search text ignoring case.
+func MovieListQuery(search, ownerId, requesterId string, featured bool) bson.M {
+	and := []bson.M{}
+	if ownerId != "" {
+		and = append(and, bson.M{"owner_id": ownerId})
+	}
+	if ownerId == "" || ownerId != requesterId {
+		and = append(and, bson.M{"visibility": enums.PUBLIC_LIST})
+	}
+	if search != "" {
+		and = append(and, bson.M{"name": primitive.Regex{Pattern: regexp.QuoteMeta(search), Options: "i"}})
+	}
+	if featured {
+		and = append(and, bson.M{"featured": true})
+	}
+	return bson.M{"$and": and}
+}
+
+func (m MovieList) Store(list MovieList) error {
+	coll := config.GetDmManager().Db.Collection(MovieListCollection)
+	_, err := coll.InsertOne(config.GetDmManager().Ctx, list)
+	if err != nil {
+		log.Println("[ERROR] Insert document:", err.Error())
+		return err
+	}
+	return nil
+}
+
+func (m MovieList) GetByID(id string) MovieList {
+	coll := config.GetDmManager().Db.Collection(MovieListCollection)
+	result := coll.FindOne(config.GetDmManager().Ctx, bson.M{"id": id})
+	res := new(MovieList)
+	err := result.Decode(res)
+	if err != nil && err != mongo.ErrNoDocuments {
+		log.Println("[ERROR]", err)
+	}
+	return *res
+}
+
+// Search returns a page of lists matching the query in sort order, without their entries.
+func (m MovieList) Search(query bson.M, pagination Pagination, sort enums.LIST_SORT) ([]MovieList, int64) {
+	var data []MovieList
+	coll := config.GetDmManager().Db.Collection(MovieListCollection)
+	skip := pagination.Page * pagination.Limit
+	findOptions := options.FindOptions{
+		Limit:      &pagination.Limit,
+		Skip:       &skip,
+		Sort:       bson.D{{Key: "updated_at", Value: -1}},
+		Projection: bson.M{"entries": 0},
+	}
+	switch sort {
+	case enums.RECENTLY_CREATED:
+		findOptions.Sort = bson.D{{Key: "created_at", Value: -1}}
+	case enums.RECENTLY_FEATURED:
+		findOptions.Sort = bson.D{{Key: "featured_at", Value: -1}, {Key: "updated_at", Value: -1}}
+	}
+	result, err := coll.Find(config.GetDmManager().Ctx, query, &findOptions)
+	if err != nil {
+		log.Println(err.Error())
+		return data, 0
+	}
+	for result.Next(context.TODO()) {
+		elemValue := new(MovieList)
+		err := result.Decode(elemValue)
+		if err != nil {
+			log.Println("[ERROR]", err)
+			break
+		}
+		data = append(data, *elemValue)
+	}
+	count, err := coll.CountDocuments(config.GetDmManager().Ctx, query)
+	if err != nil {
+		log.Println(err.Error())
+	}
+	return data, count
+}
+
+// Update replaces the list, keeping its entry count in sync with its entries. The list is only
+// replaced if it was not updated since it was read, so concurrent edits do not lose entries.
+func (m MovieList) Update(list MovieList, readUpdatedAt time.Time) error {
+	list.EntryCount = int64(len(list.Entries))
+	coll := config.GetDmManager().Db.Collection(MovieListCollection)
+	res, err := coll.ReplaceOne(config.GetDmManager().Ctx, bson.M{"id": list.ID, "updated_at": readUpdatedAt}, list)
+	if err != nil {
+		log.Println("[ERROR] Update document:", err.Error())
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return errors.New("list is changed by another request, please try again")
+	}
+	return nil
+}
+
+func (m MovieList) Delete(id string) error {
+	coll := config.GetDmManager().Db.Collection(MovieListCollection)
+	res, err := coll.DeleteOne(config.GetDmManager().Ctx, bson.M{"id": id})
+	if err != nil {
+		log.Println("[ERROR]", err)
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return errors.New("list not found")
+	}
+	return nil
+}
+
+// SetFeatured features or unfeatures the list.
+func (m MovieList) SetFeatured(id string, featured bool) error {
+	update := bson.M{"$set": bson.M{"featured": true, "featured_at": time.Now().UTC()}}
+	if !featured {
+		update = bson.M{"$set": bson.M{"featured": false}, "$unset": bson.M{"featured_at": ""}}
+	}
+	coll := config.GetDmManager().Db.Collection(MovieListCollection)
+	res, err := coll.UpdateOne(config.GetDmManager().Ctx, bson.M{"id": id}, update)
+	if err != nil {
+		log.Println("[ERROR] Update document:", err.Error())
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return errors.New("list not found")
+	}
+	return nil
+}
+
+// EnsureIndexes creates indexes used by list search.
+func (m MovieList) EnsureIndexes() error {
+	coll := config.GetDmManager().Db.Collection(MovieListCollection)
+	_, err := coll.Indexes().CreateMany(config.GetDmManager().Ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "id", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "visibility", Value: 1}, {Key: "updated_at", Value: -1}}},
+		{Keys: bson.D{{Key: "owner_id", Value: 1}, {Key: "updated_at", Value: -1}}},
+		{Keys: bson.D{{Key: "featured", Value: 1}, {Key: "featured_at", Value: -1}}},
+	})
+	if err != nil {
+		log.Println("[ERROR] Create index:", err.Error())
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"github.com/niloydeb1/Golang-Movie_API/config"
+	"github.com/niloydeb1/Golang-Movie_API/enums"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"log"
+	"time"
+)
+
+const NotificationCollection = "notificationCollection"
+
+// Notification contains an event a user is notified about.
+type Notification struct {
+	ID          string                  `json:"id" bson:"id"`
+	UserId      string                  `json:"user_id" bson:"user_id"`
+	Type        enums.NOTIFICATION_TYPE `json:"type" bson:"type"`
+	ActorId     string                  `json:"actor_id" bson:"actor_id"`
+	ContentType enums.CONTENT_TYPE      `json:"content_type,omitempty" bson:"content_type,omitempty"`
+	TargetId    string                  `json:"target_id,omitempty" bson:"target_id,omitempty"`
+	Message     string                  `json:"message" bson:"message"`
+	Read        bool                    `json:"read" bson:"read"`
+	CreatedAt   time.Time               `json:"created_at" bson:"created_at"`
+	ReadAt      *time.Time              `json:"read_at,omitempty" bson:"read_at,omitempty"`
+}
+
+// UnreadNotificationCount contains number of unread notifications of a user.
+type UnreadNotificationCount struct {
+	Unread int64 `json:"unread"`
+}
+
+func (n Notification) Store(notification Notification) error {
+	coll := config.GetDmManager().Db.Collection(NotificationCollection)
+	_, err := coll.InsertOne(config.GetDmManager().Ctx, notification)
+	if err != nil {
+		log.Println("[ERROR] Insert document:", err.Error())
+		return err
+	}
+	return nil
+}
+
+// GetByUserId returns notifications of a user, newest first. If unreadOnly is true, read
+// notifications are left out.
+func (n Notification) GetByUserId(userId string, unreadOnly bool, pagination Pagination) ([]Notification, int64) {
+	var data []Notification
+	query := bson.M{"user_id": userId}
+	if unreadOnly {
+		query["read"] = false
+	}
+	coll := config.GetDmManager().Db.Collection(NotificationCollection)
+	skip := pagination.Page * pagination.Limit
+	findOptions := options.FindOptions{
+		Limit: &pagination.Limit,
+		Skip:  &skip,
+		Sort:  bson.M{"created_at": -1},
+	}
+	result, err := coll.Find(config.GetDmManager().Ctx, query, &findOptions)
+	if err != nil {
+		log.Println(err.Error())
+		return data, 0
+	}
+	for result.Next(context.TODO()) {
+		elemValue := new(Notification)
+		err := result.Decode(elemValue)
+		if err != nil {
+			log.Println("[ERROR]", err)
+			break
+		}
+		data = append(data, *elemValue)
+	}
+	count, err := coll.CountDocuments(config.GetDmManager().Ctx, query)
+	if err != nil {
+		log.Println(err.Error())
+	}
+	return data, count
+}
+
+func (n Notification) CountUnread(userId string) int64 {
+	coll := config.GetDmManager().Db.Collection(NotificationCollection)
+	count, err := coll.CountDocuments(config.GetDmManager().Ctx, bson.M{"user_id": userId, "read": false})
+	if err != nil {
+		log.Println(err.Error())
+	}
+	return count
+}
+
+// MarkRead marks a notification of the user as read.
+func (n Notification) MarkRead(id, userId string) error {
+	filter := bson.M{"id": id, "user_id": userId}
+	update := bson.M{
+		"$set": bson.M{"read": true, "read_at": time.Now().UTC()},
+	}
+	coll := config.GetDmManager().Db.Collection(NotificationCollection)
+	res, err := coll.UpdateOne(config.GetDmManager().Ctx, filter, update)
+	if err != nil {
+		log.Println("[ERROR] Update document:", err.Error())
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return errors.New("no notification found to mark read")
+	}
+	return nil
+}
+
+// MarkAllRead marks every unread notification of the user as read and returns their number.
+func (n Notification) MarkAllRead(userId string) (int64, error) {
+	filter := bson.M{"user_id": userId, "read": false}
+	update := bson.M{
+		"$set": bson.M{"read": true, "read_at": time.Now().UTC()},
+	}
+	coll := config.GetDmManager().Db.Collection(NotificationCollection)
+	res, err := coll.UpdateMany(config.GetDmManager().Ctx, filter, update)
+	if err != nil {
+		log.Println("[ERROR] Update document:", err.Error())
+		return 0, err
+	}
+	return res.ModifiedCount, nil
+}
+
+// EnsureIndexes creates the index used to list notifications of a user.
+func (n Notification) EnsureIndexes() error {
+	coll := config.GetDmManager().Db.Collection(NotificationCollection)
+	_, err := coll.Indexes().CreateOne(config.GetDmManager().Ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "read", Value: 1}, {Key: "created_at", Value: -1}},
+	})
+	if err != nil {
+		log.Println("[ERROR] Create index:", err.Error())
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package v1
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"github.com/niloydeb1/Golang-Movie_API/config"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"log"
+	"strconv"
+	"time"
+)
+
+const PasswordResetCollection = "passwordResetCollection"
+
+var (
+	// ErrInvalidPasswordResetToken is returned for unknown, used or expired password reset tokens.
+	ErrInvalidPasswordResetToken = errors.New("password reset token is invalid or expired")
+	// ErrPasswordResetMailRateLimited is returned when a password reset is requested too often.
+	ErrPasswordResetMailRateLimited = errors.New("password reset is requested too often, please try again later")
+)
+
+// PasswordReset contains a password reset request. Only the hash of its token is stored.
+type PasswordReset struct {
+	TokenHash string     `json:"-" bson:"token_hash"`
+	UserId    string     `json:"user_id" bson:"user_id"`
+	CreatedAt time.Time  `json:"created_at" bson:"created_at"`
+	ExpiresAt time.Time  `json:"expires_at" bson:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty" bson:"used_at,omitempty"`
+}
+
+// ForgotPasswordDto contains data for requesting a password reset
+type ForgotPasswordDto struct {
+	Email string `json:"email" bson:"email"`
+}
+
+// PasswordResetConfirmDto contains data for setting a new password with a reset token
+type PasswordResetConfirmDto struct {
+	Token       string `json:"token" bson:"token"`
+	NewPassword string `json:"new_password" bson:"new_password"`
+}
+
+// Validate validates PasswordResetConfirmDto data
+func (p PasswordResetConfirmDto) Validate() error {
+	if p.Token == "" {
+		return errors.New("token is required")
+	}
+	if len(p.NewPassword) < 8 {
+		return errors.New("password length must be at least 8")
+	}
+	return nil
+}
+
+// HashPasswordResetToken returns the stored hash of a password reset token.
+func HashPasswordResetToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+// SendPasswordResetMail creates a password reset token of the user and sends it by mail. Earlier
+// unused tokens of the user are discarded. It shares the rate limit of verification mails.
+func SendPasswordResetMail(mailer Mailer, user User) error {
+	now := time.Now().UTC()
+	if VerificationMailRateLimited(user.ID, now) {
+		return ErrPasswordResetMailRateLimited
+	}
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return err
+	}
+	token := hex.EncodeToString(secret)
+	lifetime := time.Duration(config.PasswordResetTokenLifetimeMinutes) * time.Minute
+	if err := (PasswordReset{}).DeleteUnused(user.ID); err != nil {
+		return err
+	}
+	err := PasswordReset{}.Store(PasswordReset{
+		TokenHash: HashPasswordResetToken(token),
+		UserId:    user.ID,
+		CreatedAt: now,
+		ExpiresAt: now.Add(lifetime),
+	})
+	if err != nil {
+		return err
+	}
+	err = mailer.Send(Mail{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: "Hi " + user.FirstName + ",\n\nUse the token below to set a new password with PUT " + config.ApiBaseUrl +
+			"/api/v1/users/password-resets. It can be used once and expires in " +
+			strconv.FormatInt(config.PasswordResetTokenLifetimeMinutes, 10) + " minutes.\n\n" + token +
+			"\n\nIf you did not request a password reset, you can ignore this mail.\n",
+	})
+	if err != nil {
+		return err
+	}
+	return VerificationMail{}.Store(VerificationMail{UserId: user.ID, Email: user.Email, SentAt: now})
+}
+
+func (p PasswordReset) Store(reset PasswordReset) error {
+	coll := config.GetDmManager().Db.Collection(PasswordResetCollection)
+	_, err := coll.InsertOne(config.GetDmManager().Ctx, reset)
+	if err != nil {
+		log.Println("[ERROR] Insert document:", err.Error())
+		return err
+	}
+	return nil
+}
+
+// Consume marks the unused and unexpired password reset of the token as used and returns it.
+func (p PasswordReset) Consume(token string) (PasswordReset, error) {
+	now := time.Now().UTC()
+	filter := bson.M{
+		"token_hash": HashPasswordResetToken(token),
+		"used_at":    bson.M{"$exists": false},
+		"expires_at": bson.M{"$gt": now},
+	}
+	update := bson.M{
+		"$set": bson.M{"used_at": now},
+	}
+	coll := config.GetDmManager().Db.Collection(PasswordResetCollection)
+	result := coll.FindOneAndUpdate(config.GetDmManager().Ctx, filter, update)
+	res := new(PasswordReset)
+	err := result.Decode(res)
+	if err == mongo.ErrNoDocuments {
+		return PasswordReset{}, ErrInvalidPasswordResetToken
+	}
+	if err != nil {
+		log.Println("[ERROR] Update document:", err.Error())
+		return PasswordReset{}, err
+	}
+	return *res, nil
+}
+
+// DeleteUnused deletes unused password resets of the user.
+func (p PasswordReset) DeleteUnused(userId string) error {
+	coll := config.GetDmManager().Db.Collection(PasswordResetCollection)
+	_, err := coll.DeleteMany(config.GetDmManager().Ctx, bson.M{"user_id": userId, "used_at": bson.M{"$exists": false}})
+	if err != nil {
+		log.Println("[ERROR]", err)
+		return err
+	}
+	return nil
+}
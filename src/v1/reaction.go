@@ -0,0 +1,171 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"github.com/niloydeb1/Golang-Movie_API/config"
+	"github.com/niloydeb1/Golang-Movie_API/enums"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"log"
+	"strings"
+	"time"
+)
+
+const ReactionCollection = "reactionCollection"
+
+// Reaction contains a users emoji reaction to a review or comment.
+type Reaction struct {
+	ContentType enums.CONTENT_TYPE `json:"content_type" bson:"content_type"`
+	TargetId    string             `json:"target_id" bson:"target_id"`
+	UserId      string             `json:"user_id" bson:"user_id"`
+	Emoji       string             `json:"emoji" bson:"emoji"`
+	CreatedAt   time.Time          `json:"created_at" bson:"created_at"`
+}
+
+// ReactionView is a reaction of a reactor listing.
+type ReactionView struct {
+	User      UserPublicView `json:"user"`
+	Emoji     string         `json:"emoji"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+// ReactionDto contains data for reacting to content
+type ReactionDto struct {
+	Emoji string `json:"emoji" bson:"emoji"`
+}
+
+// ReactionResult contains the outcome of toggling a reaction.
+type ReactionResult struct {
+	Emoji     string           `json:"emoji"`
+	Reacted   bool             `json:"reacted"`
+	Reactions map[string]int64 `json:"reactions"`
+}
+
+// Validate validates ReactionDto data
+func (r ReactionDto) Validate() error {
+	if !IsValidReactionEmoji(r.Emoji) {
+		return errors.New("emoji must be one of [" + strings.Join(config.ReactionEmojis, "/") + "]")
+	}
+	return nil
+}
+
+// IsValidReactionEmoji returns true if the emoji is in the configured reaction set.
+func IsValidReactionEmoji(emoji string) bool {
+	for _, each := range config.ReactionEmojis {
+		if each == emoji {
+			return true
+		}
+	}
+	return false
+}
+
+// Toggle removes the reaction if the user already reacted with the emoji, otherwise stores it.
+// It returns true if the reaction is stored.
+func (r Reaction) Toggle(reaction Reaction) (bool, error) {
+	filter := bson.M{
+		"content_type": reaction.ContentType,
+		"target_id":    reaction.TargetId,
+		"user_id":      reaction.UserId,
+		"emoji":        reaction.Emoji,
+	}
+	coll := config.GetDmManager().Db.Collection(ReactionCollection)
+	data, err := coll.DeleteOne(config.GetDmManager().Ctx, filter)
+	if err != nil {
+		log.Println("[ERROR]", err)
+		return false, err
+	}
+	if data.DeletedCount > 0 {
+		return false, nil
+	}
+	_, err = coll.InsertOne(config.GetDmManager().Ctx, reaction)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			// a concurrent request stored the same reaction
+			return true, nil
+		}
+		log.Println("[ERROR] Insert document:", err.Error())
+		return false, err
+	}
+	return true, nil
+}
+
+// CountByTarget returns number of reactions to the content per emoji.
+func (r Reaction) CountByTarget(contentType enums.CONTENT_TYPE, targetId string) map[string]int64 {
+	counts := map[string]int64{}
+	pipeline := []bson.M{
+		{"$match": bson.M{"content_type": contentType, "target_id": targetId}},
+		{"$group": bson.M{"_id": "$emoji", "count": bson.M{"$sum": 1}}},
+	}
+	coll := config.GetDmManager().Db.Collection(ReactionCollection)
+	result, err := coll.Aggregate(config.GetDmManager().Ctx, pipeline)
+	if err != nil {
+		log.Println(err.Error())
+		return counts
+	}
+	for result.Next(context.TODO()) {
+		elemValue := struct {
+			Emoji string `bson:"_id"`
+			Count int64  `bson:"count"`
+		}{}
+		err := result.Decode(&elemValue)
+		if err != nil {
+			log.Println("[ERROR]", err)
+			break
+		}
+		counts[elemValue.Emoji] = elemValue.Count
+	}
+	return counts
+}
+
+// GetByTarget returns reactions to the content, newest first, leaving out reactions of the excluded
+// users. An empty emoji returns reactions of every emoji.
+func (r Reaction) GetByTarget(contentType enums.CONTENT_TYPE, targetId, emoji string, excludedUserIds []string, pagination Pagination) ([]Reaction, int64) {
+	var data []Reaction
+	query := bson.M{"content_type": contentType, "target_id": targetId}
+	if emoji != "" {
+		query["emoji"] = emoji
+	}
+	query = ExcludeUsersQuery(query, "user_id", excludedUserIds)
+	coll := config.GetDmManager().Db.Collection(ReactionCollection)
+	skip := pagination.Page * pagination.Limit
+	findOptions := options.FindOptions{
+		Limit: &pagination.Limit,
+		Skip:  &skip,
+		Sort:  bson.M{"created_at": -1},
+	}
+	result, err := coll.Find(config.GetDmManager().Ctx, query, &findOptions)
+	if err != nil {
+		log.Println(err.Error())
+		return data, 0
+	}
+	for result.Next(context.TODO()) {
+		elemValue := new(Reaction)
+		err := result.Decode(elemValue)
+		if err != nil {
+			log.Println("[ERROR]", err)
+			break
+		}
+		data = append(data, *elemValue)
+	}
+	count, err := coll.CountDocuments(config.GetDmManager().Ctx, query)
+	if err != nil {
+		log.Println(err.Error())
+	}
+	return data, count
+}
+
+// EnsureIndexes creates the unique content, user and emoji index.
+func (r Reaction) EnsureIndexes() error {
+	coll := config.GetDmManager().Db.Collection(ReactionCollection)
+	_, err := coll.Indexes().CreateOne(config.GetDmManager().Ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "content_type", Value: 1}, {Key: "target_id", Value: 1}, {Key: "user_id", Value: 1}, {Key: "emoji", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		log.Println("[ERROR] Create index:", err.Error())
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"github.com/niloydeb1/Golang-Movie_API/config"
+	"github.com/niloydeb1/Golang-Movie_API/enums"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"log"
+	"time"
+)
+
+const ReportCollection = "reportCollection"
+
+// ErrAlreadyReported is returned when a user reports the same content twice.
+var ErrAlreadyReported = errors.New("content is already reported by this user")
+
+// Report contains a users report of a review or comment.
+type Report struct {
+	ID          string              `json:"id" bson:"id"`
+	ContentType enums.CONTENT_TYPE  `json:"content_type" bson:"content_type"`
+	TargetId    string              `json:"target_id" bson:"target_id"`
+	ReporterId  string              `json:"reporter_id" bson:"reporter_id"`
+	Reason      enums.REPORT_REASON `json:"reason" bson:"reason"`
+	Details     string              `json:"details" bson:"details"`
+	CreatedAt   time.Time           `json:"created_at" bson:"created_at"`
+}
+
+// ReportDto contains data for reporting content
+type ReportDto struct {
+	Reason  enums.REPORT_REASON `json:"reason" bson:"reason"`
+	Details string              `json:"details" bson:"details"`
+}
+
+// ReportGroup contains reports of the same content.
+type ReportGroup struct {
+	ContentType     enums.CONTENT_TYPE    `json:"content_type" bson:"content_type"`
+	TargetId        string                `json:"target_id" bson:"target_id"`
+	Count           int64                 `json:"count" bson:"count"`
+	Reasons         []enums.REPORT_REASON `json:"reasons" bson:"reasons"`
+	FirstReportedAt time.Time             `json:"first_reported_at" bson:"first_reported_at"`
+	LastReportedAt  time.Time             `json:"last_reported_at" bson:"last_reported_at"`
+}
+
+// Validate validates ReportDto data
+func (r ReportDto) Validate() error {
+	switch r.Reason {
+	case enums.SPAM, enums.HARASSMENT, enums.HATE_SPEECH, enums.UNMARKED_SPOILER, enums.OFF_TOPIC:
+		return nil
+	case enums.OTHER:
+		if r.Details == "" {
+			return errors.New("details are required for reason other")
+		}
+		return nil
+	}
+	return errors.New("reason must be one of [spam/harassment/hate_speech/unmarked_spoiler/off_topic/other]")
+}
+
+// ShouldAutoHide returns true if content with the number of distinct reports and moderation
+// is hidden automatically. Content an admin already moderated is left to admins.
+func ShouldAutoHide(reports int64, moderation Moderation) bool {
+	return config.ReportAutoHideThreshold > 0 && reports >= config.ReportAutoHideThreshold &&
+		moderation.IsVisible() && moderation.ModeratorId == ""
+}
+
+func (r Report) Store(report Report) error {
+	coll := config.GetDmManager().Db.Collection(ReportCollection)
+	_, err := coll.InsertOne(config.GetDmManager().Ctx, report)
+	if err != nil {
+		log.Println("[ERROR] Insert document:", err.Error())
+		if mongo.IsDuplicateKeyError(err) {
+			return ErrAlreadyReported
+		}
+		return err
+	}
+	return nil
+}
+
+// CountByTarget returns number of reports of the content. As a user can report content only once,
+// it is the number of distinct reporters.
+func (r Report) CountByTarget(contentType enums.CONTENT_TYPE, targetId string) int64 {
+	coll := config.GetDmManager().Db.Collection(ReportCollection)
+	count, err := coll.CountDocuments(config.GetDmManager().Ctx, bson.M{"content_type": contentType, "target_id": targetId})
+	if err != nil {
+		log.Println(err.Error())
+	}
+	return count
+}
+
+func (r Report) GetByTarget(contentType enums.CONTENT_TYPE, targetId string, pagination Pagination) ([]Report, int64) {
+	var data []Report
+	query := bson.M{
+		"$and": []bson.M{
+			{"content_type": contentType},
+			{"target_id": targetId},
+		},
+	}
+	coll := config.GetDmManager().Db.Collection(ReportCollection)
+	skip := pagination.Page * pagination.Limit
+	findOptions := options.FindOptions{
+		Limit: &pagination.Limit,
+		Skip:  &skip,
+		Sort:  bson.M{"created_at": -1},
+	}
+	result, err := coll.Find(config.GetDmManager().Ctx, query, &findOptions)
+	if err != nil {
+		log.Println(err.Error())
+		return data, 0
+	}
+	for result.Next(context.TODO()) {
+		elemValue := new(Report)
+		err := result.Decode(elemValue)
+		if err != nil {
+			log.Println("[ERROR]", err)
+			break
+		}
+		data = append(data, *elemValue)
+	}
+	count, err := coll.CountDocuments(config.GetDmManager().Ctx, query)
+	if err != nil {
+		log.Println(err.Error())
+	}
+	return data, count
+}
+
+// GetGroupedByTarget returns reports grouped by reported content, most reported first.
+func (r Report) GetGroupedByTarget(query bson.M, pagination Pagination) ([]ReportGroup, int64) {
+	var data []ReportGroup
+	if query == nil {
+		query = bson.M{}
+	}
+	group := []bson.M{
+		{"$match": query},
+		{"$group": bson.M{
+			"_id":               bson.M{"content_type": "$content_type", "target_id": "$target_id"},
+			"count":             bson.M{"$sum": 1},
+			"reasons":           bson.M{"$addToSet": "$reason"},
+			"first_reported_at": bson.M{"$min": "$created_at"},
+			"last_reported_at":  bson.M{"$max": "$created_at"},
+		}},
+	}
+	pipeline := append(group[:len(group):len(group)],
+		bson.M{"$sort": bson.D{{Key: "count", Value: -1}, {Key: "last_reported_at", Value: -1}}},
+		bson.M{"$skip": pagination.Page * pagination.Limit},
+	)
+	// A zero limit means no limit, as it does for find, but $limit rejects it.
+	if pagination.Limit > 0 {
+		pipeline = append(pipeline, bson.M{"$limit": pagination.Limit})
+	}
+	pipeline = append(pipeline,
+		bson.M{"$project": bson.M{
+			"_id":               0,
+			"content_type":      "$_id.content_type",
+			"target_id":         "$_id.target_id",
+			"count":             1,
+			"reasons":           1,
+			"first_reported_at": 1,
+			"last_reported_at":  1,
+		}},
+	)
+	coll := config.GetDmManager().Db.Collection(ReportCollection)
+	result, err := coll.Aggregate(config.GetDmManager().Ctx, pipeline)
+	if err != nil {
+		log.Println(err.Error())
+		return data, 0
+	}
+	for result.Next(context.TODO()) {
+		elemValue := new(ReportGroup)
+		err := result.Decode(elemValue)
+		if err != nil {
+			log.Println("[ERROR]", err)
+			break
+		}
+		data = append(data, *elemValue)
+	}
+	countPipeline := append(group[:len(group):len(group)], bson.M{"$count": "total"})
+	countResult, err := coll.Aggregate(config.GetDmManager().Ctx, countPipeline)
+	if err != nil {
+		log.Println(err.Error())
+		return data, 0
+	}
+	count := struct {
+		Total int64 `bson:"total"`
+	}{}
+	if countResult.Next(context.TODO()) {
+		if err := countResult.Decode(&count); err != nil {
+			log.Println("[ERROR]", err)
+		}
+	}
+	return data, count.Total
+}
+
+// EnsureIndexes creates the unique content and reporter index.
+func (r Report) EnsureIndexes() error {
+	coll := config.GetDmManager().Db.Collection(ReportCollection)
+	_, err := coll.Indexes().CreateOne(config.GetDmManager().Ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "content_type", Value: 1}, {Key: "target_id", Value: 1}, {Key: "reporter_id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		log.Println("[ERROR] Create index:", err.Error())
+		return err
+	}
+	return nil
+}
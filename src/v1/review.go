@@ -4,7 +4,9 @@ import (
 	"context"
 	"errors"
 	"github.com/niloydeb1/Golang-Movie_API/config"
+	"github.com/niloydeb1/Golang-Movie_API/enums"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"log"
 	"time"
@@ -12,14 +14,27 @@ import (
 
 const ReviewCollection = "reviewCollection"
 
+// ErrReviewAlreadyExists is returned when a reviewer already reviewed the movie.
+var ErrReviewAlreadyExists = errors.New("review already exists for this movie")
+
 type Review struct {
-	ID            string        `json:"id" bson:"id"`
-	Movie         ReviewedMovie `json:"movie" bson:"movie"`
-	ReviewerEmail string        `json:"email" bson:"email"`
-	ReviewerId    string        `json:"reviewer_id" bson:"reviewer_id"`
-	ReviewTitle   string        `json:"review_title" bson:"review_title"`
-	Description   string        `json:"description" bson:"description"`
-	CreatedAt     time.Time     `json:"created_at" bson:"created_at"`
+	ID                  string           `json:"id" bson:"id"`
+	Movie               ReviewedMovie    `json:"movie" bson:"movie"`
+	ReviewerEmail       string           `json:"email" bson:"email"`
+	ReviewerId          string           `json:"reviewer_id" bson:"reviewer_id"`
+	ReviewTitle         string           `json:"review_title" bson:"review_title"`
+	Description         string           `json:"description" bson:"description"`
+	RenderedDescription string           `json:"rendered_description,omitempty" bson:"rendered_description,omitempty"`
+	Mentions            []Mention        `json:"mentions" bson:"mentions"`
+	Spoiler             bool             `json:"spoiler" bson:"spoiler"`
+	SpoilerMasked       bool             `json:"spoiler_masked,omitempty" bson:"-"`
+	CreatedAt           time.Time        `json:"created_at" bson:"created_at"`
+	EditedAt            *time.Time       `json:"edited_at,omitempty" bson:"edited_at,omitempty"`
+	Helpful             int64            `json:"helpful_count" bson:"helpful_count"`
+	Unhelpful           int64            `json:"unhelpful_count" bson:"unhelpful_count"`
+	HelpfulScore        float64          `json:"helpful_score" bson:"helpful_score"`
+	Reactions           map[string]int64 `json:"reactions" bson:"reactions"`
+	Moderation          Moderation       `json:"moderation" bson:"moderation"`
 }
 
 type ReviewedMovie struct {
@@ -30,6 +45,14 @@ type ReviewedMovie struct {
 	Director string `json:"Director" bson:"Director"`
 }
 
+// ReviewDuplicate contains reviews that share the same reviewer and movie.
+type ReviewDuplicate struct {
+	ReviewerId string   `json:"reviewer_id" bson:"reviewer_id"`
+	MovieId    string   `json:"movie_id" bson:"movie_id"`
+	Count      int64    `json:"count" bson:"count"`
+	ReviewIds  []string `json:"review_ids" bson:"review_ids"`
+}
+
 func (r Review) Validate() error {
 	if r.Movie.ID == "" {
 		return errors.New("movie id is not provided")
@@ -83,24 +106,204 @@ func (r Review) GetByMovieTitle(title string) []Review {
 	return data
 }
 
+func (r Review) GetByReviewerAndMovie(reviewerId, movieId string) Review {
+	query := bson.M{
+		"$and": []bson.M{
+			{"reviewer_id": reviewerId},
+			{"movie.id": movieId},
+		},
+	}
+	coll := config.GetDmManager().Db.Collection(ReviewCollection)
+	result := coll.FindOne(config.GetDmManager().Ctx, query, nil)
+	res := new(Review)
+	err := result.Decode(res)
+	if err != nil && err != mongo.ErrNoDocuments {
+		log.Println("[ERROR]", err)
+	}
+	return *res
+}
+
 func (r Review) Store(review Review) error {
 	coll := config.GetDmManager().Db.Collection(ReviewCollection)
 	_, err := coll.InsertOne(config.GetDmManager().Ctx, review)
 	if err != nil {
 		log.Println("[ERROR] Insert document:", err.Error())
+		if mongo.IsDuplicateKeyError(err) {
+			return ErrReviewAlreadyExists
+		}
+		return err
+	}
+	return nil
+}
+
+// GetDuplicates returns every reviewer and movie pair that has more than one review.
+func (r Review) GetDuplicates() []ReviewDuplicate {
+	var data []ReviewDuplicate
+	pipeline := []bson.M{
+		{"$sort": bson.M{"created_at": 1}},
+		{"$group": bson.M{
+			"_id":        bson.M{"reviewer_id": "$reviewer_id", "movie_id": "$movie.id"},
+			"count":      bson.M{"$sum": 1},
+			"review_ids": bson.M{"$push": "$id"},
+		}},
+		{"$match": bson.M{"count": bson.M{"$gt": 1}}},
+		{"$project": bson.M{
+			"_id":         0,
+			"reviewer_id": "$_id.reviewer_id",
+			"movie_id":    "$_id.movie_id",
+			"count":       1,
+			"review_ids":  1,
+		}},
+	}
+	coll := config.GetDmManager().Db.Collection(ReviewCollection)
+	result, err := coll.Aggregate(config.GetDmManager().Ctx, pipeline)
+	if err != nil {
+		log.Println(err.Error())
+		return data
+	}
+	for result.Next(context.TODO()) {
+		elemValue := new(ReviewDuplicate)
+		err := result.Decode(elemValue)
+		if err != nil {
+			log.Println("[ERROR]", err)
+			break
+		}
+		data = append(data, *elemValue)
+	}
+	return data
+}
+
+// EnsureIndexes creates the unique reviewer and movie index. It fails while duplicates exist.
+func (r Review) EnsureIndexes() error {
+	coll := config.GetDmManager().Db.Collection(ReviewCollection)
+	_, err := coll.Indexes().CreateOne(config.GetDmManager().Ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "reviewer_id", Value: 1}, {Key: "movie.id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		log.Println("[ERROR] Create index:", err.Error())
+		return err
+	}
+	return nil
+}
+
+// Update writes the editable fields of a review. Denormalized counters are left to UpdateHelpfulness and UpdateReactions.
+func (r Review) Update(review Review) error {
+	filter := bson.M{
+		"$and": []bson.M{
+			{"id": review.ID},
+		},
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"review_title":         review.ReviewTitle,
+			"description":          review.Description,
+			"rendered_description": review.RenderedDescription,
+			"mentions":             review.Mentions,
+			"spoiler":              review.Spoiler,
+			"edited_at":            review.EditedAt,
+			"moderation":           review.Moderation,
+		},
+	}
+	upsert := false
+	after := options.After
+	opt := options.FindOneAndUpdateOptions{
+		ReturnDocument: &after,
+		Upsert:         &upsert,
+	}
+	coll := config.GetDmManager().Db.Collection(ReviewCollection)
+	result := coll.FindOneAndUpdate(config.GetDmManager().Ctx, filter, update, &opt)
+	if result.Err() != nil {
+		log.Println("[ERROR] Update document:", result.Err())
+		return result.Err()
+	}
+	return nil
+}
+
+// UpdateReviewerEmail sets the email of reviews of the user and of mentions of the user in reviews.
+func (r Review) UpdateReviewerEmail(userId, email string) error {
+	coll := config.GetDmManager().Db.Collection(ReviewCollection)
+	_, err := coll.UpdateMany(config.GetDmManager().Ctx, bson.M{"reviewer_id": userId}, bson.M{"$set": bson.M{"email": email}})
+	if err != nil {
+		log.Println("[ERROR] Update document:", err.Error())
+		return err
+	}
+	return updateMentionEmail(ReviewCollection, userId, email)
+}
+
+// UpdateHelpfulness sets the denormalized vote counts and Wilson score of a review.
+func (r Review) UpdateHelpfulness(id string, helpful, unhelpful int64) error {
+	filter := bson.M{"id": id}
+	update := bson.M{
+		"$set": bson.M{
+			"helpful_count":   helpful,
+			"unhelpful_count": unhelpful,
+			"helpful_score":   WilsonScore(helpful, unhelpful),
+		},
+	}
+	coll := config.GetDmManager().Db.Collection(ReviewCollection)
+	_, err := coll.UpdateOne(config.GetDmManager().Ctx, filter, update)
+	if err != nil {
+		log.Println("[ERROR] Update document:", err.Error())
 		return err
 	}
 	return nil
 }
 
-func (r Review) Search(query bson.M, pagination Pagination) ([]Review, int64) {
+// UpdateReactions sets the denormalized reaction counts of a review.
+func (r Review) UpdateReactions(id string, reactions map[string]int64) error {
+	filter := bson.M{"id": id}
+	update := bson.M{
+		"$set": bson.M{"reactions": reactions},
+	}
+	coll := config.GetDmManager().Db.Collection(ReviewCollection)
+	res, err := coll.UpdateOne(config.GetDmManager().Ctx, filter, update)
+	if err != nil {
+		log.Println("[ERROR] Update document:", err.Error())
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return errors.New("no review found to update")
+	}
+	return nil
+}
+
+// RenderMentions resolves mentions of the description and renders them as links. Users who
+// blocked the reviewer are not mentioned.
+func (r Review) RenderMentions() Review {
+	r.Mentions = WithoutBlockers(r.ReviewerId, ResolveMentions(r.Description))
+	r.RenderedDescription = RenderMentions(r.Description, r.Mentions)
+	return r
+}
+
+func (r Review) UpdateModeration(id string, moderation Moderation) error {
+	filter := bson.M{"id": id}
+	update := bson.M{
+		"$set": bson.M{"moderation": moderation},
+	}
+	coll := config.GetDmManager().Db.Collection(ReviewCollection)
+	res, err := coll.UpdateOne(config.GetDmManager().Ctx, filter, update)
+	if err != nil {
+		log.Println("[ERROR] Update document:", err.Error())
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return errors.New("no review found to moderate")
+	}
+	return nil
+}
+
+func (r Review) Search(query bson.M, pagination Pagination, sort enums.REVIEW_SORT) ([]Review, int64) {
 	var data []Review
 	coll := config.GetDmManager().Db.Collection(ReviewCollection)
 	skip := pagination.Page * pagination.Limit
 	findOptions := options.FindOptions{
 		Limit: &pagination.Limit,
 		Skip:  &skip,
-		Sort:  bson.M{"created_at": -1},
+		Sort:  bson.D{{Key: "created_at", Value: -1}},
+	}
+	if sort == enums.HELPFUL {
+		findOptions.Sort = bson.D{{Key: "helpful_score", Value: -1}, {Key: "created_at", Value: -1}}
 	}
 	result, err := coll.Find(config.GetDmManager().Ctx, query, &findOptions)
 	if err != nil {
@@ -0,0 +1,88 @@
+package v1
+
+import (
+	"context"
+	"github.com/niloydeb1/Golang-Movie_API/config"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"log"
+	"time"
+)
+
+const ReviewRevisionCollection = "reviewRevisionCollection"
+
+// ReviewRevision contains the state of a review before an edit and what the edit changed.
+type ReviewRevision struct {
+	ID          string       `json:"id" bson:"id"`
+	ReviewId    string       `json:"review_id" bson:"review_id"`
+	EditorId    string       `json:"editor_id" bson:"editor_id"`
+	ReviewTitle string       `json:"review_title" bson:"review_title"`
+	Description string       `json:"description" bson:"description"`
+	Changes     []TextChange `json:"changes" bson:"changes"`
+	CreatedAt   time.Time    `json:"created_at" bson:"created_at"`
+}
+
+// NewReviewRevision returns the revision that turns the old review into the edited one.
+func NewReviewRevision(id, editorId string, old, edited Review) ReviewRevision {
+	revision := ReviewRevision{
+		ID:          id,
+		ReviewId:    old.ID,
+		EditorId:    editorId,
+		ReviewTitle: old.ReviewTitle,
+		Description: old.Description,
+		Changes:     []TextChange{},
+		CreatedAt:   time.Now().UTC(),
+	}
+	if change := DiffText("review_title", old.ReviewTitle, edited.ReviewTitle); change != nil {
+		revision.Changes = append(revision.Changes, *change)
+	}
+	if change := DiffText("description", old.Description, edited.Description); change != nil {
+		revision.Changes = append(revision.Changes, *change)
+	}
+	return revision
+}
+
+func (r ReviewRevision) Store(revision ReviewRevision) error {
+	coll := config.GetDmManager().Db.Collection(ReviewRevisionCollection)
+	_, err := coll.InsertOne(config.GetDmManager().Ctx, revision)
+	if err != nil {
+		log.Println("[ERROR] Insert document:", err.Error())
+		return err
+	}
+	return nil
+}
+
+func (r ReviewRevision) GetByReviewId(reviewId string, pagination Pagination) ([]ReviewRevision, int64) {
+	var data []ReviewRevision
+	query := bson.M{
+		"$and": []bson.M{
+			{"review_id": reviewId},
+		},
+	}
+	coll := config.GetDmManager().Db.Collection(ReviewRevisionCollection)
+	skip := pagination.Page * pagination.Limit
+	findOptions := options.FindOptions{
+		Limit: &pagination.Limit,
+		Skip:  &skip,
+		Sort:  bson.M{"created_at": -1},
+	}
+	result, err := coll.Find(config.GetDmManager().Ctx, query, &findOptions)
+	if err != nil {
+		log.Println(err.Error())
+		return data, 0
+	}
+	for result.Next(context.TODO()) {
+		elemValue := new(ReviewRevision)
+		err := result.Decode(elemValue)
+		if err != nil {
+			log.Println("[ERROR]", err)
+			break
+		}
+		data = append(data, *elemValue)
+	}
+	count, err := coll.CountDocuments(config.GetDmManager().Ctx, query)
+	if err != nil {
+		log.Println(err.Error())
+	}
+	return data, count
+}
@@ -0,0 +1,112 @@
+package v1
+
+import (
+	"errors"
+	"github.com/niloydeb1/Golang-Movie_API/config"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"log"
+	"math"
+	"time"
+)
+
+const ReviewVoteCollection = "reviewVoteCollection"
+
+// wilsonZ is the z-score of the 95% confidence level used by WilsonScore.
+const wilsonZ = 1.96
+
+// ReviewVote contains a users helpfulness vote of a review.
+type ReviewVote struct {
+	ReviewId  string    `json:"review_id" bson:"review_id"`
+	VoterId   string    `json:"voter_id" bson:"voter_id"`
+	Helpful   bool      `json:"helpful" bson:"helpful"`
+	CreatedAt time.Time `json:"created_at" bson:"created_at"`
+}
+
+// ReviewVoteDto contains data for voting a review
+type ReviewVoteDto struct {
+	Helpful *bool `json:"helpful" bson:"helpful"`
+}
+
+// Validate validates ReviewVoteDto data
+func (v ReviewVoteDto) Validate() error {
+	if v.Helpful == nil {
+		return errors.New("helpful is not provided")
+	}
+	return nil
+}
+
+// WilsonScore returns the lower bound of the Wilson score interval of the helpful ratio.
+func WilsonScore(helpful, unhelpful int64) float64 {
+	n := float64(helpful + unhelpful)
+	if n == 0 {
+		return 0
+	}
+	p := float64(helpful) / n
+	z2 := wilsonZ * wilsonZ
+	return (p + z2/(2*n) - wilsonZ*math.Sqrt((p*(1-p)+z2/(4*n))/n)) / (1 + z2/n)
+}
+
+// Upsert stores the vote, replacing a previous vote of the same voter.
+func (v ReviewVote) Upsert(vote ReviewVote) error {
+	filter := bson.M{
+		"$and": []bson.M{
+			{"review_id": vote.ReviewId},
+			{"voter_id": vote.VoterId},
+		},
+	}
+	update := bson.M{
+		"$set": vote,
+	}
+	coll := config.GetDmManager().Db.Collection(ReviewVoteCollection)
+	_, err := coll.UpdateOne(config.GetDmManager().Ctx, filter, update, options.Update().SetUpsert(true))
+	if err != nil {
+		log.Println("[ERROR] Upsert document:", err.Error())
+		return err
+	}
+	return nil
+}
+
+func (v ReviewVote) Delete(reviewId, voterId string) error {
+	coll := config.GetDmManager().Db.Collection(ReviewVoteCollection)
+	filter := bson.M{"review_id": reviewId, "voter_id": voterId}
+	data, err := coll.DeleteOne(config.GetDmManager().Ctx, filter)
+	if err != nil {
+		log.Println("[ERROR]", err)
+		return err
+	}
+	if data.DeletedCount == 0 {
+		log.Println("No data found to delete!")
+		return errors.New("no vote found to retract")
+	}
+	return nil
+}
+
+// CountByReviewId returns the number of helpful and unhelpful votes of a review.
+func (v ReviewVote) CountByReviewId(reviewId string) (int64, int64) {
+	coll := config.GetDmManager().Db.Collection(ReviewVoteCollection)
+	helpful, err := coll.CountDocuments(config.GetDmManager().Ctx, bson.M{"review_id": reviewId, "helpful": true})
+	if err != nil {
+		log.Println(err.Error())
+	}
+	unhelpful, err := coll.CountDocuments(config.GetDmManager().Ctx, bson.M{"review_id": reviewId, "helpful": false})
+	if err != nil {
+		log.Println(err.Error())
+	}
+	return helpful, unhelpful
+}
+
+// EnsureIndexes creates the unique review and voter index.
+func (v ReviewVote) EnsureIndexes() error {
+	coll := config.GetDmManager().Db.Collection(ReviewVoteCollection)
+	_, err := coll.Indexes().CreateOne(config.GetDmManager().Ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "review_id", Value: 1}, {Key: "voter_id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		log.Println("[ERROR] Create index:", err.Error())
+		return err
+	}
+	return nil
+}
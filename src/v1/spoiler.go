@@ -0,0 +1,55 @@
+package v1
+
+import "regexp"
+
+// SpoilerPlaceholder replaces masked spoiler text.
+const SpoilerPlaceholder = "[spoiler]"
+
+// spoilerMarkup matches inline spoilers written as [spoiler]text[/spoiler].
+var spoilerMarkup = regexp.MustCompile(`(?is)\[spoiler\].*?\[/spoiler\]`)
+
+// MaskSpoilers replaces every inline spoiler of the text with SpoilerPlaceholder.
+func MaskSpoilers(text string) string {
+	return spoilerMarkup.ReplaceAllString(text, SpoilerPlaceholder)
+}
+
+// HasSpoilerMarkup returns true if the text contains inline spoilers.
+func HasSpoilerMarkup(text string) bool {
+	return spoilerMarkup.MatchString(text)
+}
+
+// MaskSpoilers returns the review with spoiler content redacted. The description of a review
+// flagged as spoiler is hidden completely, otherwise only its inline spoilers are.
+func (r Review) MaskSpoilers() Review {
+	if r.Spoiler {
+		r.Description = SpoilerPlaceholder
+		r.RenderedDescription = ""
+		r.Mentions = []Mention{}
+		r.SpoilerMasked = true
+	} else if HasSpoilerMarkup(r.Description) {
+		r.Description = MaskSpoilers(r.Description)
+		r.RenderedDescription = MaskSpoilers(r.RenderedDescription)
+		r.SpoilerMasked = true
+	}
+	if HasSpoilerMarkup(r.ReviewTitle) {
+		r.ReviewTitle = MaskSpoilers(r.ReviewTitle)
+		r.SpoilerMasked = true
+	}
+	return r
+}
+
+// MaskSpoilers returns the comment with spoiler content redacted. The text of a comment
+// flagged as spoiler is hidden completely, otherwise only its inline spoilers are.
+func (c Comment) MaskSpoilers() Comment {
+	if c.Spoiler {
+		c.Comment = SpoilerPlaceholder
+		c.RenderedComment = ""
+		c.Mentions = []Mention{}
+		c.SpoilerMasked = true
+	} else if HasSpoilerMarkup(c.Comment) {
+		c.Comment = MaskSpoilers(c.Comment)
+		c.RenderedComment = MaskSpoilers(c.RenderedComment)
+		c.SpoilerMasked = true
+	}
+	return c
+}
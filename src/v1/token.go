@@ -63,6 +63,15 @@ func (t TokenService) GetByUID(uid string) Token {
 	return res
 }
 
+// GetAllByUID returns every stored token of the user, one per session.
+func (t TokenService) GetAllByUID(uid string) []Token {
+	data := []Token{}
+	if err := findAll(TokenCollection, bson.M{"uid": uid}, nil, &data); err != nil {
+		log.Println("[ERROR]", err)
+	}
+	return data
+}
+
 func (t TokenService) Store(token Token) error {
 	coll := config.GetDmManager().Db.Collection(TokenCollection)
 	_, err := coll.InsertOne(config.GetDmManager().Ctx, token)
@@ -85,6 +94,17 @@ func (t TokenService) Delete(uid string) error {
 	return err
 }
 
+// DeleteByUID revokes every token of the user.
+func (t TokenService) DeleteByUID(uid string) error {
+	coll := config.GetDmManager().Db.Collection(TokenCollection)
+	_, err := coll.DeleteMany(config.GetDmManager().Ctx, bson.M{"uid": uid})
+	if err != nil {
+		log.Println("[ERROR]", err)
+		return err
+	}
+	return nil
+}
+
 func (t TokenService) Update(token string, refreshToken string, existingToken string) error {
 	oldTokenObj := t.GetByToken(existingToken)
 	if oldTokenObj.Uid == "" {
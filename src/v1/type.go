@@ -5,9 +5,14 @@ import (
 	"errors"
 	"github.com/niloydeb1/Golang-Movie_API/enums"
 	"net/mail"
+	"regexp"
+	"strings"
 	"time"
 )
 
+// e164Pattern matches phone numbers in E.164 format.
+var e164Pattern = regexp.MustCompile(`^\+[1-9][0-9]{1,14}$`)
+
 // RsaKeys contains RSA keys.
 type RsaKeys struct {
 	PrivateKey *rsa.PrivateKey
@@ -38,6 +43,19 @@ type UserTokenDto struct {
 	Role      enums.ROLE   `json:"role" bson:"role"`
 }
 
+// NewUserTokenDto returns token claims of the stored user.
+func NewUserTokenDto(user User) UserTokenDto {
+	return UserTokenDto{
+		ID:        user.ID,
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+		Email:     user.Email,
+		Phone:     user.Phone,
+		Status:    user.Status,
+		Role:      user.Role,
+	}
+}
+
 // RefreshTokenDto contains refresh token.
 type RefreshTokenDto struct {
 	RefreshToken string `json:"refresh_token" bson:"refresh_token"`
@@ -58,11 +76,50 @@ type UserRegistrationDto struct {
 
 // PasswordResetDto contains data for password reset
 type PasswordResetDto struct {
-	Email           string `json:"email" bson:"email"`
 	CurrentPassword string `json:"current_password" bson:"current_password"`
 	NewPassword     string `json:"new_password" bson:"new_password"`
 }
 
+// UserProfileDto contains data for editing own profile, empty fields are left unchanged
+type UserProfileDto struct {
+	FirstName *string `json:"first_name" bson:"first_name"`
+	LastName  *string `json:"last_name" bson:"last_name"`
+	Phone     *string `json:"phone" bson:"phone"`
+}
+
+// EmailChangeDto contains data for changing own email
+type EmailChangeDto struct {
+	Email           string `json:"email" bson:"email"`
+	CurrentPassword string `json:"current_password" bson:"current_password"`
+}
+
+// ReviewUpdateDto contains data for editing a review
+type ReviewUpdateDto struct {
+	ReviewTitle string `json:"review_title" bson:"review_title"`
+	Description string `json:"description" bson:"description"`
+	Spoiler     *bool  `json:"spoiler" bson:"spoiler"`
+}
+
+// CommentUpdateDto contains data for editing a comment
+type CommentUpdateDto struct {
+	Comment string `json:"comment" bson:"comment"`
+	Spoiler *bool  `json:"spoiler" bson:"spoiler"`
+}
+
+// ReviewConflictDto points at the review that already exists for a movie
+type ReviewConflictDto struct {
+	ReviewId string `json:"review_id" bson:"review_id"`
+	Link     string `json:"link" bson:"link"`
+}
+
+// NewReviewConflictDto returns ReviewConflictDto of a review
+func NewReviewConflictDto(reviewId string) ReviewConflictDto {
+	return ReviewConflictDto{
+		ReviewId: reviewId,
+		Link:     "/api/v1/reviews/" + reviewId,
+	}
+}
+
 // JWTPayLoad contains payload of JWT token.
 type JWTPayLoad struct {
 	AccessToken  string `json:"access_token" bson:"access_token"`
@@ -96,6 +153,35 @@ func (u UserRegistrationDto) Validate() error {
 	return nil
 }
 
+// Validate validates UserProfileDto data
+func (u UserProfileDto) Validate() error {
+	if u.FirstName == nil && u.LastName == nil && u.Phone == nil {
+		return errors.New("no profile field is provided")
+	}
+	if u.FirstName != nil && strings.TrimSpace(*u.FirstName) == "" {
+		return errors.New("first name can not be empty")
+	}
+	if u.LastName != nil && strings.TrimSpace(*u.LastName) == "" {
+		return errors.New("last name can not be empty")
+	}
+	if u.Phone != nil && *u.Phone != "" && !e164Pattern.MatchString(*u.Phone) {
+		return errors.New("phone must be an E.164 number, e.g. +8801707007007")
+	}
+	return nil
+}
+
+// Validate validates EmailChangeDto data
+func (e EmailChangeDto) Validate() error {
+	if e.Email == "" {
+		return errors.New("email is required")
+	}
+	if e.CurrentPassword == "" {
+		return errors.New("current password is required")
+	}
+	_, err := mail.ParseAddress(e.Email)
+	return err
+}
+
 // GetUserFromUserRegistrationDto converts User from UserRegistrationDto
 func GetUserFromUserRegistrationDto(u UserRegistrationDto) User {
 	user := User{
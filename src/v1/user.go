@@ -2,12 +2,16 @@ package v1
 
 import (
 	"context"
+	"errors"
 	"github.com/niloydeb1/Golang-Movie_API/config"
 	"github.com/niloydeb1/Golang-Movie_API/enums"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"golang.org/x/crypto/bcrypt"
 	"log"
+	"regexp"
 	"time"
 )
 
@@ -19,24 +23,89 @@ type User struct {
 	LastName           string                 `json:"last_name" bson:"last_name"`
 	Email              string                 `json:"email" bson:"email" `
 	Phone              string                 `json:"phone" bson:"phone" `
-	Password           string                 `json:"password" bson:"password" `
+	Password           string                 `json:"-" bson:"password" `
 	Status             enums.STATUS           `json:"status" bson:"status"`
 	CreatedDate        time.Time              `json:"created_date" bson:"created_date"`
 	UpdatedDate        time.Time              `json:"updated_date" bson:"updated_date"`
 	Role  			   enums.ROLE			  `json:"role" bson:"role"`
+	Preferences        UserPreferences        `json:"preferences" bson:"preferences"`
+	PendingEmail       string                 `json:"pending_email,omitempty" bson:"pending_email,omitempty"`
+	Avatar             string                 `json:"avatar,omitempty" bson:"avatar,omitempty"`
 }
 
-func (u User) GetUsers(status enums.STATUS) []User {
-	var results []User
-	query := bson.M{
-		"$and": []bson.M{
-			{"status": status},
-		},
+// UserPreferences contains users personal settings.
+type UserPreferences struct {
+	ShowSpoilers bool `json:"show_spoilers" bson:"show_spoilers"`
+}
+
+// UserFilter contains filters of the user directory. Zero values are not applied.
+type UserFilter struct {
+	Search      string
+	Role        enums.ROLE
+	Status      enums.STATUS
+	CreatedFrom time.Time
+	CreatedTo   time.Time
+}
+
+// Query returns the query matching the filter. Search matches the prefix of first name, last
+// name or email, ignoring case. Deleted users are left out unless they are asked for by status.
+func (f UserFilter) Query() bson.M {
+	var and []bson.M
+	if f.Search != "" {
+		prefix := primitive.Regex{Pattern: "^" + regexp.QuoteMeta(f.Search), Options: "i"}
+		and = append(and, bson.M{"$or": []bson.M{
+			{"first_name": prefix},
+			{"last_name": prefix},
+			{"email": prefix},
+		}})
+	}
+	if f.Role != "" {
+		and = append(and, bson.M{"role": f.Role})
+	}
+	if f.Status != "" {
+		and = append(and, bson.M{"status": f.Status})
+	} else {
+		and = append(and, bson.M{"status": bson.M{"$ne": enums.DELETED}})
+	}
+	if !f.CreatedFrom.IsZero() {
+		and = append(and, bson.M{"created_date": bson.M{"$gte": f.CreatedFrom}})
+	}
+	if !f.CreatedTo.IsZero() {
+		and = append(and, bson.M{"created_date": bson.M{"$lt": f.CreatedTo}})
 	}
+	return bson.M{"$and": and}
+}
+
+// userFindOptions returns find options sorting users by the sort order. Names and emails are
+// compared ignoring case.
+func userFindOptions(sort enums.USER_SORT) *options.FindOptions {
+	findOptions := options.Find()
+	switch sort {
+	case enums.USERS_OLDEST:
+		findOptions.SetSort(bson.D{{Key: "created_date", Value: 1}})
+	case enums.USERS_BY_NAME:
+		findOptions.SetSort(bson.D{{Key: "first_name", Value: 1}, {Key: "last_name", Value: 1}, {Key: "id", Value: 1}})
+		findOptions.SetCollation(&options.Collation{Locale: "en", Strength: 2})
+	case enums.USERS_BY_EMAIL:
+		findOptions.SetSort(bson.D{{Key: "email", Value: 1}})
+		findOptions.SetCollation(&options.Collation{Locale: "en", Strength: 2})
+	default:
+		findOptions.SetSort(bson.D{{Key: "created_date", Value: -1}})
+	}
+	return findOptions
+}
+
+// Search returns a page of users matching the query and the total count of matching users.
+func (u User) Search(query bson.M, pagination Pagination, sort enums.USER_SORT) ([]User, int64) {
+	var data []User
 	coll := config.GetDmManager().Db.Collection(UserCollection)
-	result, err := coll.Find(config.GetDmManager().Ctx, query, nil)
+	findOptions := userFindOptions(sort)
+	findOptions.SetSkip(pagination.Page * pagination.Limit)
+	findOptions.SetLimit(pagination.Limit)
+	result, err := coll.Find(config.GetDmManager().Ctx, query, findOptions)
 	if err != nil {
 		log.Println(err.Error())
+		return data, 0
 	}
 	for result.Next(context.TODO()) {
 		elemValue := new(User)
@@ -45,9 +114,51 @@ func (u User) GetUsers(status enums.STATUS) []User {
 			log.Println("[ERROR]", err)
 			break
 		}
-		results = append(results, *elemValue)
+		data = append(data, *elemValue)
 	}
-	return results
+	count, err := coll.CountDocuments(config.GetDmManager().Ctx, query)
+	if err != nil {
+		log.Println(err.Error())
+	}
+	return data, count
+}
+
+// Each calls fn for every user matching the query in sort order, without loading all of them
+// at once. It stops at the first error returned by fn.
+func (u User) Each(query bson.M, sort enums.USER_SORT, fn func(User) error) error {
+	coll := config.GetDmManager().Db.Collection(UserCollection)
+	result, err := coll.Find(config.GetDmManager().Ctx, query, userFindOptions(sort))
+	if err != nil {
+		log.Println(err.Error())
+		return err
+	}
+	defer result.Close(context.TODO())
+	for result.Next(context.TODO()) {
+		elemValue := new(User)
+		if err := result.Decode(elemValue); err != nil {
+			log.Println("[ERROR]", err)
+			return err
+		}
+		if err := fn(*elemValue); err != nil {
+			return err
+		}
+	}
+	return result.Err()
+}
+
+// EnsureIndexes creates indexes used by the user directory.
+func (u User) EnsureIndexes() error {
+	coll := config.GetDmManager().Db.Collection(UserCollection)
+	_, err := coll.Indexes().CreateMany(config.GetDmManager().Ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "email", Value: 1}}},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "created_date", Value: -1}}},
+		{Keys: bson.D{{Key: "first_name", Value: 1}, {Key: "last_name", Value: 1}}},
+	})
+	if err != nil {
+		log.Println("[ERROR] Create index:", err.Error())
+		return err
+	}
+	return nil
 }
 
 func (u User) UpdateStatus(id string, status enums.STATUS) error {
@@ -75,6 +186,87 @@ func (u User) UpdateStatus(id string, status enums.STATUS) error {
 	return nil
 }
 
+func (u User) UpdatePreferences(id string, preferences UserPreferences) error {
+	filter := bson.M{"id": id}
+	update := bson.M{
+		"$set": bson.M{"preferences": preferences, "updated_date": time.Now().UTC()},
+	}
+	coll := config.GetDmManager().Db.Collection(UserCollection)
+	res, err := coll.UpdateOne(config.GetDmManager().Ctx, filter, update)
+	if err != nil {
+		log.Println("[ERROR] Update document:", err.Error())
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return errors.New("user not found")
+	}
+	return nil
+}
+
+func (u User) UpdateRole(id string, role enums.ROLE) error {
+	filter := bson.M{"id": id}
+	update := bson.M{
+		"$set": bson.M{"role": role, "updated_date": time.Now().UTC()},
+	}
+	coll := config.GetDmManager().Db.Collection(UserCollection)
+	res, err := coll.UpdateOne(config.GetDmManager().Ctx, filter, update)
+	if err != nil {
+		log.Println("[ERROR] Update document:", err.Error())
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return errors.New("user not found")
+	}
+	return nil
+}
+
+// UpdateProfile sets the provided profile fields of a user.
+func (u User) UpdateProfile(id string, profile UserProfileDto) error {
+	set := bson.M{"updated_date": time.Now().UTC()}
+	if profile.FirstName != nil {
+		set["first_name"] = *profile.FirstName
+	}
+	if profile.LastName != nil {
+		set["last_name"] = *profile.LastName
+	}
+	if profile.Phone != nil {
+		set["phone"] = *profile.Phone
+	}
+	return u.update(id, set)
+}
+
+// SetPendingEmail stores the email a user is changing to until it is verified.
+func (u User) SetPendingEmail(id, email string) error {
+	return u.update(id, bson.M{"pending_email": email, "updated_date": time.Now().UTC()})
+}
+
+// ChangeEmail replaces email of a user with the verified pending email.
+func (u User) ChangeEmail(id, email string) error {
+	return u.update(id, bson.M{"email": email, "pending_email": "", "updated_date": time.Now().UTC()})
+}
+
+// UpdateAvatar sets avatar url of a user, an empty url removes the avatar.
+func (u User) UpdateAvatar(id, avatar string) error {
+	return u.update(id, bson.M{"avatar": avatar, "updated_date": time.Now().UTC()})
+}
+
+func (u User) update(id string, set bson.M) error {
+	filter := bson.M{"id": id}
+	update := bson.M{
+		"$set": set,
+	}
+	coll := config.GetDmManager().Db.Collection(UserCollection)
+	res, err := coll.UpdateOne(config.GetDmManager().Ctx, filter, update)
+	if err != nil {
+		log.Println("[ERROR] Update document:", err.Error())
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return errors.New("user not found")
+	}
+	return nil
+}
+
 func (u User) UpdatePassword(user User) error {
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
 	if err != nil {
@@ -163,6 +355,30 @@ func (u User) Get() []User {
 	return results
 }
 
+// GetByIDs returns users of the ids by id. Ids of users that do not exist are left out.
+func (u User) GetByIDs(ids []string) map[string]User {
+	results := map[string]User{}
+	if len(ids) == 0 {
+		return results
+	}
+	coll := config.GetDmManager().Db.Collection(UserCollection)
+	result, err := coll.Find(config.GetDmManager().Ctx, bson.M{"id": bson.M{"$in": ids}})
+	if err != nil {
+		log.Println(err.Error())
+		return results
+	}
+	for result.Next(context.TODO()) {
+		elemValue := new(User)
+		err := result.Decode(elemValue)
+		if err != nil {
+			log.Println("[ERROR]", err)
+			break
+		}
+		results[elemValue.ID] = *elemValue
+	}
+	return results
+}
+
 func (u User) GetByID(id string) User {
 	var res User
 	query := bson.M{
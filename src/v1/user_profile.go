@@ -0,0 +1,163 @@
+package v1
+
+import (
+	"context"
+	"github.com/niloydeb1/Golang-Movie_API/config"
+	"github.com/niloydeb1/Golang-Movie_API/enums"
+	"go.mongodb.org/mongo-driver/bson"
+	"log"
+	"math"
+	"time"
+)
+
+// FavouriteGenreLimit is the number of favourite genres shown on a user profile.
+const FavouriteGenreLimit = 3
+
+// UserProfile is the public presence of a user with stats of their reviews and comments.
+type UserProfile struct {
+	User            UserPublicView `json:"user"`
+	DisplayName     string         `json:"display_name"`
+	JoinedAt        time.Time      `json:"joined_at"`
+	ReviewCount     int64          `json:"review_count"`
+	RatingCount     int64          `json:"rating_count"`
+	AverageRating   *float64       `json:"average_rating"`
+	CommentCount    int64          `json:"comment_count"`
+	FollowerCount   int64          `json:"follower_count"`
+	FollowingCount  int64          `json:"following_count"`
+	FavouriteGenres []GenreCount   `json:"favourite_genres"`
+}
+
+// GenreCount contains number of reviews a user wrote of movies of the genre.
+type GenreCount struct {
+	Genre string `json:"genre" bson:"_id"`
+	Count int64  `json:"count" bson:"count"`
+}
+
+// UserReviewsQuery returns the query of visible reviews written by the user.
+func UserReviewsQuery(userId string) bson.M {
+	return VisibleQuery(bson.M{"reviewer_id": userId})
+}
+
+// UserCommentsQuery returns the query of visible comments written by the user, leaving out deleted
+// ones and comments on private lists.
+func UserCommentsQuery(userId string) bson.M {
+	query := bson.M{"commenter_id": userId, "deleted": bson.M{"$ne": true}}
+	if listIds := privateListIdsCommentedBy(userId); len(listIds) > 0 {
+		query["list_id"] = bson.M{"$nin": listIds}
+	}
+	return VisibleQuery(query)
+}
+
+// privateListIdsCommentedBy returns ids of private lists the user commented on.
+func privateListIdsCommentedBy(userId string) []string {
+	listIds, err := config.GetDmManager().Db.Collection(CommentCollection).Distinct(config.GetDmManager().Ctx,
+		"list_id", bson.M{"commenter_id": userId, "list_id": bson.M{"$nin": []interface{}{"", nil}}})
+	if err != nil {
+		log.Println(err.Error())
+		return nil
+	}
+	if len(listIds) == 0 {
+		return nil
+	}
+	var lists []MovieList
+	err = findAll(MovieListCollection, bson.M{"id": bson.M{"$in": listIds}, "visibility": enums.PRIVATE_LIST}, nil, &lists)
+	if err != nil {
+		log.Println(err.Error())
+		return nil
+	}
+	ids := make([]string, len(lists))
+	for i, list := range lists {
+		ids[i] = list.ID
+	}
+	return ids
+}
+
+// NewUserProfile returns the public profile of the user. Only visible reviews and comments are counted.
+func NewUserProfile(user User) UserProfile {
+	profile := UserProfile{
+		User:            NewUserPublicView(user),
+		DisplayName:     user.FirstName + " " + user.LastName,
+		JoinedAt:        user.CreatedDate,
+		FavouriteGenres: []GenreCount{},
+	}
+	profile.ReviewCount, profile.RatingCount, profile.AverageRating = reviewStats(user.ID)
+	commentColl := config.GetDmManager().Db.Collection(CommentCollection)
+	count, err := commentColl.CountDocuments(config.GetDmManager().Ctx, UserCommentsQuery(user.ID))
+	if err != nil {
+		log.Println(err.Error())
+	}
+	profile.CommentCount = count
+	profile.FavouriteGenres = favouriteGenres(user.ID, FavouriteGenreLimit)
+	profile.FollowerCount = Follow{}.CountFollowers(user.ID)
+	profile.FollowingCount = Follow{}.CountFollowing(user.ID)
+	return profile
+}
+
+// reviewStats returns number of reviews of the user, how many of them give a rating and the
+// average rating rounded to one decimal. The average is nil if no review gives a rating.
+func reviewStats(userId string) (int64, int64, *float64) {
+	pipeline := []bson.M{
+		{"$match": UserReviewsQuery(userId)},
+		{"$group": bson.M{
+			"_id":          nil,
+			"count":        bson.M{"$sum": 1},
+			"rating_count": bson.M{"$sum": bson.M{"$cond": []interface{}{bson.M{"$gt": []interface{}{"$rating", nil}}, 1, 0}}},
+			"average":      bson.M{"$avg": "$rating"},
+		}},
+	}
+	coll := config.GetDmManager().Db.Collection(ReviewCollection)
+	result, err := coll.Aggregate(config.GetDmManager().Ctx, pipeline)
+	if err != nil {
+		log.Println(err.Error())
+		return 0, 0, nil
+	}
+	defer result.Close(context.TODO())
+	stats := struct {
+		Count       int64    `bson:"count"`
+		RatingCount int64    `bson:"rating_count"`
+		Average     *float64 `bson:"average"`
+	}{}
+	if result.Next(context.TODO()) {
+		if err := result.Decode(&stats); err != nil {
+			log.Println("[ERROR]", err)
+			return 0, 0, nil
+		}
+	}
+	if stats.Average != nil {
+		rounded := math.Round(*stats.Average*10) / 10
+		stats.Average = &rounded
+	}
+	return stats.Count, stats.RatingCount, stats.Average
+}
+
+// favouriteGenres returns genres the user reviewed most, derived from the comma separated genres
+// of reviewed movies. Ties are broken by genre name.
+func favouriteGenres(userId string, limit int64) []GenreCount {
+	genres := []GenreCount{}
+	pipeline := []bson.M{
+		{"$match": UserReviewsQuery(userId)},
+		{"$project": bson.M{"genre": bson.M{"$split": []interface{}{"$movie.Genre", ","}}}},
+		{"$unwind": "$genre"},
+		{"$project": bson.M{"genre": bson.M{"$trim": bson.M{"input": "$genre"}}}},
+		{"$match": bson.M{"genre": bson.M{"$nin": []string{"", "N/A"}}}},
+		{"$group": bson.M{"_id": "$genre", "count": bson.M{"$sum": 1}}},
+		{"$sort": bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}},
+		{"$limit": limit},
+	}
+	coll := config.GetDmManager().Db.Collection(ReviewCollection)
+	result, err := coll.Aggregate(config.GetDmManager().Ctx, pipeline)
+	if err != nil {
+		log.Println(err.Error())
+		return genres
+	}
+	for result.Next(context.TODO()) {
+		elemValue := GenreCount{}
+		err := result.Decode(&elemValue)
+		if err != nil {
+			log.Println("[ERROR]", err)
+			break
+		}
+		genres = append(genres, elemValue)
+	}
+	return genres
+}
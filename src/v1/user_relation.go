@@ -0,0 +1,196 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"github.com/niloydeb1/Golang-Movie_API/config"
+	"github.com/niloydeb1/Golang-Movie_API/enums"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"log"
+	"time"
+)
+
+const UserRelationCollection = "userRelationCollection"
+
+// ErrRelationExists is returned when the user already blocked or muted the other user.
+var ErrRelationExists = errors.New("relation already exists")
+
+// UserRelation is a block or mute a user set on another user.
+type UserRelation struct {
+	UserId    string              `json:"user_id" bson:"user_id"`
+	TargetId  string              `json:"target_id" bson:"target_id"`
+	Type      enums.RELATION_TYPE `json:"type" bson:"type"`
+	CreatedAt time.Time           `json:"created_at" bson:"created_at"`
+}
+
+// UserRelationView is a blocked or muted user of a relation listing.
+type UserRelationView struct {
+	User      UserPublicView `json:"user"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+// ExcludeUsersQuery restricts the query to documents whose field is none of the user ids.
+func ExcludeUsersQuery(query bson.M, field string, userIds []string) bson.M {
+	if len(userIds) == 0 {
+		return query
+	}
+	exclude := bson.M{field: bson.M{"$nin": userIds}}
+	if len(query) == 0 {
+		return exclude
+	}
+	return bson.M{"$and": []bson.M{query, exclude}}
+}
+
+// Store stores the relation. It returns ErrRelationExists if the relation exists.
+func (u UserRelation) Store(relation UserRelation) error {
+	coll := config.GetDmManager().Db.Collection(UserRelationCollection)
+	_, err := coll.InsertOne(config.GetDmManager().Ctx, relation)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrRelationExists
+	}
+	if err != nil {
+		log.Println("[ERROR] Insert document:", err.Error())
+		return err
+	}
+	return nil
+}
+
+// Delete removes the relation of the type the user set on the target.
+func (u UserRelation) Delete(userId, targetId string, relationType enums.RELATION_TYPE) error {
+	coll := config.GetDmManager().Db.Collection(UserRelationCollection)
+	res, err := coll.DeleteOne(config.GetDmManager().Ctx, bson.M{"user_id": userId, "target_id": targetId, "type": relationType})
+	if err != nil {
+		log.Println("[ERROR]", err)
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return errors.New("relation does not exist")
+	}
+	return nil
+}
+
+// IsBlockedByAny returns true if any of the users blocked the target. Empty user ids are ignored.
+func (u UserRelation) IsBlockedByAny(targetId string, userIds ...string) bool {
+	return u.exists(bson.M{"user_id": bson.M{"$in": userIds}, "target_id": targetId, "type": enums.BLOCK})
+}
+
+// BlockersOf returns which of the users blocked the target.
+func (u UserRelation) BlockersOf(targetId string, userIds []string) map[string]bool {
+	blockers := map[string]bool{}
+	coll := config.GetDmManager().Db.Collection(UserRelationCollection)
+	result, err := coll.Find(config.GetDmManager().Ctx, bson.M{"user_id": bson.M{"$in": userIds}, "target_id": targetId, "type": enums.BLOCK})
+	if err != nil {
+		log.Println(err.Error())
+		return blockers
+	}
+	for result.Next(context.TODO()) {
+		elemValue := UserRelation{}
+		if err := result.Decode(&elemValue); err != nil {
+			log.Println("[ERROR]", err)
+			break
+		}
+		blockers[elemValue.UserId] = true
+	}
+	return blockers
+}
+
+// IsSilencedBy returns true if the user blocked or muted the target.
+func (u UserRelation) IsSilencedBy(userId, targetId string) bool {
+	return u.exists(bson.M{"user_id": userId, "target_id": targetId})
+}
+
+func (u UserRelation) exists(query bson.M) bool {
+	coll := config.GetDmManager().Db.Collection(UserRelationCollection)
+	count, err := coll.CountDocuments(config.GetDmManager().Ctx, query, options.Count().SetLimit(1))
+	if err != nil {
+		log.Println(err.Error())
+	}
+	return count > 0
+}
+
+// HiddenUserIds returns ids of users whose content is hidden from the user, which are users the
+// user blocked or muted and users who blocked the user.
+func (u UserRelation) HiddenUserIds(userId string) []string {
+	ids := []string{}
+	if userId == "" {
+		return ids
+	}
+	query := bson.M{"$or": []bson.M{
+		{"user_id": userId},
+		{"target_id": userId, "type": enums.BLOCK},
+	}}
+	coll := config.GetDmManager().Db.Collection(UserRelationCollection)
+	result, err := coll.Find(config.GetDmManager().Ctx, query)
+	if err != nil {
+		log.Println(err.Error())
+		return ids
+	}
+	seen := map[string]bool{}
+	for result.Next(context.TODO()) {
+		elemValue := UserRelation{}
+		if err := result.Decode(&elemValue); err != nil {
+			log.Println("[ERROR]", err)
+			break
+		}
+		id := elemValue.TargetId
+		if id == userId {
+			id = elemValue.UserId
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// GetByUserId returns a page of relations of the type the user set, latest first.
+func (u UserRelation) GetByUserId(userId string, relationType enums.RELATION_TYPE, pagination Pagination) ([]UserRelation, int64) {
+	var data []UserRelation
+	query := bson.M{"user_id": userId, "type": relationType}
+	coll := config.GetDmManager().Db.Collection(UserRelationCollection)
+	skip := pagination.Page * pagination.Limit
+	findOptions := options.FindOptions{
+		Limit: &pagination.Limit,
+		Skip:  &skip,
+		Sort:  bson.D{{Key: "created_at", Value: -1}},
+	}
+	result, err := coll.Find(config.GetDmManager().Ctx, query, &findOptions)
+	if err != nil {
+		log.Println(err.Error())
+		return data, 0
+	}
+	for result.Next(context.TODO()) {
+		elemValue := new(UserRelation)
+		err := result.Decode(elemValue)
+		if err != nil {
+			log.Println("[ERROR]", err)
+			break
+		}
+		data = append(data, *elemValue)
+	}
+	count, err := coll.CountDocuments(config.GetDmManager().Ctx, query)
+	if err != nil {
+		log.Println(err.Error())
+	}
+	return data, count
+}
+
+// EnsureIndexes creates the index that keeps a relation unique and the index of blockers of a user.
+func (u UserRelation) EnsureIndexes() error {
+	coll := config.GetDmManager().Db.Collection(UserRelationCollection)
+	_, err := coll.Indexes().CreateMany(config.GetDmManager().Ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "target_id", Value: 1}, {Key: "type", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{Keys: bson.D{{Key: "target_id", Value: 1}, {Key: "type", Value: 1}}},
+	})
+	if err != nil {
+		log.Println("[ERROR] Create index:", err.Error())
+		return err
+	}
+	return nil
+}
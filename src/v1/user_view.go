@@ -0,0 +1,99 @@
+package v1
+
+import (
+	"github.com/niloydeb1/Golang-Movie_API/enums"
+	"time"
+)
+
+// UserPublicView is the representation of a user visible to everyone.
+type UserPublicView struct {
+	ID        string `json:"id"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Avatar    string `json:"avatar,omitempty"`
+}
+
+// UserSelfView is the representation of a user visible to the user itself.
+type UserSelfView struct {
+	ID           string          `json:"id"`
+	FirstName    string          `json:"first_name"`
+	LastName     string          `json:"last_name"`
+	Email        string          `json:"email"`
+	Phone        string          `json:"phone"`
+	Status       enums.STATUS    `json:"status"`
+	Role         enums.ROLE      `json:"role"`
+	Preferences  UserPreferences `json:"preferences"`
+	PendingEmail string          `json:"pending_email,omitempty"`
+	Avatar       string          `json:"avatar,omitempty"`
+	CreatedDate  time.Time       `json:"created_date"`
+	UpdatedDate  time.Time       `json:"updated_date"`
+}
+
+// UserAdminView is the representation of a user visible to admins.
+type UserAdminView struct {
+	ID           string       `json:"id"`
+	FirstName    string       `json:"first_name"`
+	LastName     string       `json:"last_name"`
+	Email        string       `json:"email"`
+	Phone        string       `json:"phone"`
+	Status       enums.STATUS `json:"status"`
+	Role         enums.ROLE   `json:"role"`
+	PendingEmail string       `json:"pending_email,omitempty"`
+	Avatar       string       `json:"avatar,omitempty"`
+	CreatedDate  time.Time    `json:"created_date"`
+	UpdatedDate  time.Time    `json:"updated_date"`
+}
+
+// NewUserPublicView maps a user to its public view.
+func NewUserPublicView(user User) UserPublicView {
+	return UserPublicView{
+		ID:        user.ID,
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+		Avatar:    user.Avatar,
+	}
+}
+
+// NewUserSelfView maps a user to the view returned to the user itself.
+func NewUserSelfView(user User) UserSelfView {
+	return UserSelfView{
+		ID:           user.ID,
+		FirstName:    user.FirstName,
+		LastName:     user.LastName,
+		Email:        user.Email,
+		Phone:        user.Phone,
+		Status:       user.Status,
+		Role:         user.Role,
+		Preferences:  user.Preferences,
+		PendingEmail: user.PendingEmail,
+		Avatar:       user.Avatar,
+		CreatedDate:  user.CreatedDate,
+		UpdatedDate:  user.UpdatedDate,
+	}
+}
+
+// NewUserAdminView maps a user to the view returned to admins.
+func NewUserAdminView(user User) UserAdminView {
+	return UserAdminView{
+		ID:           user.ID,
+		FirstName:    user.FirstName,
+		LastName:     user.LastName,
+		Email:        user.Email,
+		Phone:        user.Phone,
+		Status:       user.Status,
+		Role:         user.Role,
+		PendingEmail: user.PendingEmail,
+		Avatar:       user.Avatar,
+		CreatedDate:  user.CreatedDate,
+		UpdatedDate:  user.UpdatedDate,
+	}
+}
+
+// NewUserAdminViews maps users to the views returned to admins.
+func NewUserAdminViews(users []User) []UserAdminView {
+	views := make([]UserAdminView, 0, len(users))
+	for _, user := range users {
+		views = append(views, NewUserAdminView(user))
+	}
+	return views
+}
@@ -0,0 +1,211 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"github.com/niloydeb1/Golang-Movie_API/config"
+	"github.com/niloydeb1/Golang-Movie_API/enums"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"log"
+	"time"
+)
+
+const WatchlistCollection = "watchlistCollection"
+
+// WatchlistNotesMaxLength is the maximum length of notes of a watchlist entry.
+const WatchlistNotesMaxLength = 1000
+
+// ErrWatchlistEntryExists is returned when the movie is already on the watchlist of the user.
+var ErrWatchlistEntryExists = errors.New("movie is already on the watchlist")
+
+// WatchlistEntry is a movie a user saved to watch later.
+type WatchlistEntry struct {
+	ID        string                   `json:"id" bson:"id"`
+	UserId    string                   `json:"user_id" bson:"user_id"`
+	Movie     MovieSummary             `json:"movie" bson:"movie"`
+	Priority  enums.WATCHLIST_PRIORITY `json:"priority" bson:"priority"`
+	Notes     string                   `json:"notes" bson:"notes"`
+	AddedAt   time.Time                `json:"added_at" bson:"added_at"`
+	UpdatedAt time.Time                `json:"updated_at" bson:"updated_at"`
+}
+
+// WatchlistEntryDto contains data for adding a movie to the watchlist, either by movie id or title.
+type WatchlistEntryDto struct {
+	MovieId  string                   `json:"movie_id" bson:"movie_id"`
+	Title    string                   `json:"title" bson:"title"`
+	Priority enums.WATCHLIST_PRIORITY `json:"priority" bson:"priority"`
+	Notes    string                   `json:"notes" bson:"notes"`
+}
+
+// WatchlistUpdateDto contains data for updating a watchlist entry, nil fields are left unchanged.
+type WatchlistUpdateDto struct {
+	Priority *enums.WATCHLIST_PRIORITY `json:"priority" bson:"priority"`
+	Notes    *string                   `json:"notes" bson:"notes"`
+}
+
+// Validate validates WatchlistEntryDto data
+func (w WatchlistEntryDto) Validate() error {
+	if w.MovieId == "" && w.Title == "" {
+		return errors.New("movie id or title is required")
+	}
+	if w.Priority != "" {
+		if err := ValidateWatchlistPriority(w.Priority); err != nil {
+			return err
+		}
+	}
+	return validateWatchlistNotes(w.Notes)
+}
+
+// Validate validates WatchlistUpdateDto data
+func (w WatchlistUpdateDto) Validate() error {
+	if w.Priority != nil {
+		if err := ValidateWatchlistPriority(*w.Priority); err != nil {
+			return err
+		}
+	}
+	if w.Notes != nil {
+		return validateWatchlistNotes(*w.Notes)
+	}
+	return nil
+}
+
+// ValidateWatchlistPriority returns an error if the priority is not a known watchlist priority.
+func ValidateWatchlistPriority(priority enums.WATCHLIST_PRIORITY) error {
+	switch priority {
+	case enums.LOW_PRIORITY, enums.NORMAL_PRIORITY, enums.HIGH_PRIORITY:
+		return nil
+	}
+	return errors.New("priority must be one of [low/normal/high]")
+}
+
+func validateWatchlistNotes(notes string) error {
+	if len([]rune(notes)) > WatchlistNotesMaxLength {
+		return errors.New("notes must be at most 1000 characters")
+	}
+	return nil
+}
+
+// Store stores the watchlist entry. It returns ErrWatchlistEntryExists if the movie is already on
+// the watchlist of the user.
+func (w WatchlistEntry) Store(entry WatchlistEntry) error {
+	coll := config.GetDmManager().Db.Collection(WatchlistCollection)
+	_, err := coll.InsertOne(config.GetDmManager().Ctx, entry)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrWatchlistEntryExists
+	}
+	if err != nil {
+		log.Println("[ERROR] Insert document:", err.Error())
+		return err
+	}
+	return nil
+}
+
+// GetByUserAndMovie returns the watchlist entry of the movie of the user.
+func (w WatchlistEntry) GetByUserAndMovie(userId, movieId string) WatchlistEntry {
+	query := bson.M{"user_id": userId, "movie.id": movieId}
+	coll := config.GetDmManager().Db.Collection(WatchlistCollection)
+	result := coll.FindOne(config.GetDmManager().Ctx, query)
+	res := new(WatchlistEntry)
+	err := result.Decode(res)
+	if err != nil && err != mongo.ErrNoDocuments {
+		log.Println("[ERROR]", err)
+	}
+	return *res
+}
+
+// GetByUserId returns a page of the watchlist of the user in sort order. An empty priority returns
+// entries of every priority.
+func (w WatchlistEntry) GetByUserId(userId string, priority enums.WATCHLIST_PRIORITY, pagination Pagination, sort enums.WATCHLIST_SORT) ([]WatchlistEntry, int64) {
+	var data []WatchlistEntry
+	query := bson.M{"user_id": userId}
+	if priority != "" {
+		query["priority"] = priority
+	}
+	coll := config.GetDmManager().Db.Collection(WatchlistCollection)
+	skip := pagination.Page * pagination.Limit
+	findOptions := options.FindOptions{
+		Limit: &pagination.Limit,
+		Skip:  &skip,
+		Sort:  bson.D{{Key: "added_at", Value: -1}},
+	}
+	switch sort {
+	case enums.RELEASE_YEAR:
+		findOptions.Sort = bson.D{{Key: "movie.year", Value: -1}, {Key: "added_at", Value: -1}}
+	case enums.TOP_RATED:
+		findOptions.Sort = bson.D{{Key: "movie.rating", Value: -1}, {Key: "added_at", Value: -1}}
+	}
+	result, err := coll.Find(config.GetDmManager().Ctx, query, &findOptions)
+	if err != nil {
+		log.Println(err.Error())
+		return data, 0
+	}
+	for result.Next(context.TODO()) {
+		elemValue := new(WatchlistEntry)
+		err := result.Decode(elemValue)
+		if err != nil {
+			log.Println("[ERROR]", err)
+			break
+		}
+		data = append(data, *elemValue)
+	}
+	count, err := coll.CountDocuments(config.GetDmManager().Ctx, query)
+	if err != nil {
+		log.Println(err.Error())
+	}
+	return data, count
+}
+
+// Update sets priority and notes of the watchlist entry of the movie of the user.
+func (w WatchlistEntry) Update(userId, movieId string, update WatchlistUpdateDto) (WatchlistEntry, error) {
+	set := bson.M{"updated_at": time.Now().UTC()}
+	if update.Priority != nil {
+		set["priority"] = *update.Priority
+	}
+	if update.Notes != nil {
+		set["notes"] = *update.Notes
+	}
+	after := options.After
+	coll := config.GetDmManager().Db.Collection(WatchlistCollection)
+	result := coll.FindOneAndUpdate(config.GetDmManager().Ctx, bson.M{"user_id": userId, "movie.id": movieId},
+		bson.M{"$set": set}, &options.FindOneAndUpdateOptions{ReturnDocument: &after})
+	res := new(WatchlistEntry)
+	err := result.Decode(res)
+	if err == mongo.ErrNoDocuments {
+		return WatchlistEntry{}, errors.New("movie is not on the watchlist")
+	}
+	if err != nil {
+		log.Println("[ERROR] Update document:", err.Error())
+		return WatchlistEntry{}, err
+	}
+	return *res, nil
+}
+
+// Delete removes the movie from the watchlist of the user.
+func (w WatchlistEntry) Delete(userId, movieId string) error {
+	coll := config.GetDmManager().Db.Collection(WatchlistCollection)
+	res, err := coll.DeleteOne(config.GetDmManager().Ctx, bson.M{"user_id": userId, "movie.id": movieId})
+	if err != nil {
+		log.Println("[ERROR]", err)
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return errors.New("movie is not on the watchlist")
+	}
+	return nil
+}
+
+// EnsureIndexes creates the index that keeps a movie once on the watchlist of a user.
+func (w WatchlistEntry) EnsureIndexes() error {
+	coll := config.GetDmManager().Db.Collection(WatchlistCollection)
+	_, err := coll.Indexes().CreateOne(config.GetDmManager().Ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "movie.id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		log.Println("[ERROR] Create index:", err.Error())
+		return err
+	}
+	return nil
+}